@@ -4,4 +4,6 @@ type ArgParser interface {
 	HasExecutable() bool
 	GetExecutable() string
 	GetArguments() []string
+	// HasExecFlag returns true if UDL should be replaced by the application
+	HasExecFlag() bool
 }
@@ -2,21 +2,38 @@ package argparsers
 
 import "os"
 
+// execFlag is an optional first argument that replaces UDL with the application, rather than running it as a child
+// process.
+const execFlag = "--exec"
+
 type SimpleArgParser struct {
 }
 
 func (a SimpleArgParser) HasExecutable() bool {
-	return len(os.Args) > 1 && os.Getenv("UDL_RUNNING_TEST") != "true"
+	return len(a.getArgs()) > 0 && os.Getenv("UDL_RUNNING_TEST") != "true"
 }
 
 func (a SimpleArgParser) GetExecutable() string {
-	return os.Args[1]
+	return a.getArgs()[0]
 }
 
 func (a SimpleArgParser) GetArguments() []string {
 	args := []string{}
-	if len(os.Args) > 2 {
-		args = os.Args[2:]
+	if len(a.getArgs()) > 1 {
+		args = a.getArgs()[1:]
+	}
+	return args
+}
+
+func (a SimpleArgParser) HasExecFlag() bool {
+	return len(os.Args) > 1 && os.Args[1] == execFlag
+}
+
+// getArgs returns the arguments passed to UDL, without the exec flag.
+func (a SimpleArgParser) getArgs() []string {
+	args := os.Args[1:]
+	if a.HasExecFlag() {
+		args = args[1:]
 	}
 	return args
 }
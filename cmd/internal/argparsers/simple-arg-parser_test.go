@@ -0,0 +1,46 @@
+package argparsers
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSimpleArgParser(t *testing.T) {
+	tests := []struct {
+		args       []string
+		executable string
+		arguments  []string
+		exec       bool
+	}{
+		{[]string{"udl", "python", "/app/main.py"}, "python", []string{"/app/main.py"}, false},
+		{[]string{"udl", "--exec", "python", "/app/main.py"}, "python", []string{"/app/main.py"}, true},
+		{[]string{"udl", "--exec", "nginx"}, "nginx", []string{}, true},
+		{[]string{"udl", "python", "--exec"}, "python", []string{"--exec"}, false},
+	}
+
+	args := os.Args
+	defer func() { os.Args = args }()
+
+	for _, test := range tests {
+		os.Args = test.args
+		parser := SimpleArgParser{}
+
+		if !parser.HasExecutable() || parser.GetExecutable() != test.executable {
+			t.Fatal("The executable of " + strings.Join(test.args, " ") + " must be " + test.executable)
+		}
+
+		if strings.Join(parser.GetArguments(), " ") != strings.Join(test.arguments, " ") {
+			t.Fatal("The arguments of " + strings.Join(test.args, " ") + " must be " + strings.Join(test.arguments, " "))
+		}
+
+		if parser.HasExecFlag() != test.exec {
+			t.Fatal("The exec flag of " + strings.Join(test.args, " ") + " was not parsed")
+		}
+	}
+
+	os.Args = []string{"udl", "--exec"}
+	if (SimpleArgParser{}).HasExecutable() {
+		t.Fatal("The exec flag must not be treated as the executable")
+	}
+}
@@ -0,0 +1,17 @@
+package customerror
+
+import "strings"
+
+// UnappliedError captures the env vars that could not be applied, like values set in files that do not exist
+type UnappliedError struct {
+	Errors []*UdlError
+}
+
+func (e *UnappliedError) Error() string {
+	messages := []string{}
+	for _, err := range e.Errors {
+		messages = append(messages, err.EnvVar+": "+err.Error())
+	}
+
+	return "the following environment variables were not applied: " + strings.Join(messages, "; ")
+}
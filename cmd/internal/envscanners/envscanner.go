@@ -1,5 +1,16 @@
 package envscanners
 
+import "github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+
 type EnvScanner interface {
 	ProcessEnvVars() error
 }
+
+// unappliedError returns an error listing the env vars that could not be applied, or nil if every env var was applied.
+func unappliedError(unapplied []*customerror.UdlError) error {
+	if len(unapplied) == 0 {
+		return nil
+	}
+
+	return &customerror.UnappliedError{Errors: unapplied}
+}
@@ -2,9 +2,11 @@ package envscanners
 
 import (
 	b64 "encoding/base64"
+	"errors"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"github.com/rs/zerolog/log"
 	"strings"
@@ -16,6 +18,8 @@ type FileB64WriterEnvScanner struct {
 }
 
 func (f FileB64WriterEnvScanner) ProcessEnvVars() error {
+	unapplied := []*customerror.UdlError{}
+
 	for _, e := range f.Env.GetAllEnvVars() {
 
 		if i := strings.Index(e, "="); i >= 0 {
@@ -25,7 +29,13 @@ func (f FileB64WriterEnvScanner) ProcessEnvVars() error {
 			for _, p := range prefixes.EnvVarPrefixes {
 				prefix := p + "UDL_WRITEB64FILE["
 				if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, "]") {
-					file := strings.TrimLeft(strings.TrimRight(key, "]"), prefix)
+					file, err := f.getFilePath(key[len(prefix)-1:])
+
+					if err != nil {
+						unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+						continue
+					}
+
 					contents, err := b64.StdEncoding.DecodeString(value)
 
 					if err != nil {
@@ -49,5 +59,20 @@ func (f FileB64WriterEnvScanner) ProcessEnvVars() error {
 		}
 	}
 
-	return nil
+	return unappliedError(unapplied)
+}
+
+// getFilePath returns the file name from the bracketed segment after the directive name.
+func (f FileB64WriterEnvScanner) getFilePath(key string) (string, error) {
+	segments, remaining, err := stringutil.BracketedSegments(key, 1)
+
+	if err != nil {
+		return "", err
+	}
+
+	if remaining != "" {
+		return "", errors.New("unexpected text \"" + remaining + "\" after the file name")
+	}
+
+	return stringutil.Unescape(segments[0]), nil
 }
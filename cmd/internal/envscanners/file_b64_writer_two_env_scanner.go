@@ -2,13 +2,12 @@ package envscanners
 
 import (
 	b64 "encoding/base64"
-	"errors"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"github.com/rs/zerolog/log"
-	"regexp"
 	"strings"
 )
 
@@ -18,6 +17,8 @@ type FileB64WriterEnvScannerTwo struct {
 }
 
 func (f FileB64WriterEnvScannerTwo) ProcessEnvVars() error {
+	unapplied := []*customerror.UdlError{}
+
 	for _, e := range f.Env.GetAllEnvVars() {
 
 		if i := strings.Index(e, "="); i >= 0 {
@@ -29,7 +30,8 @@ func (f FileB64WriterEnvScannerTwo) ProcessEnvVars() error {
 					file, encodedContents, err := f.getFilePath(value)
 
 					if err != nil {
-						return err
+						unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+						continue
 					}
 
 					contents, err := b64.StdEncoding.DecodeString(encodedContents)
@@ -55,16 +57,15 @@ func (f FileB64WriterEnvScannerTwo) ProcessEnvVars() error {
 		}
 	}
 
-	return nil
+	return unappliedError(unapplied)
 }
 
 func (f FileB64WriterEnvScannerTwo) getFilePath(key string) (string, string, error) {
-	rgx := regexp.MustCompile("\\[([^\\[\\]]+)](.*)")
-	rs := rgx.FindStringSubmatch(key)
+	segments, contents, err := stringutil.BracketedSegments(key, 1)
 
-	if rs != nil && len(rs) == 3 {
-		return rs[1], rs[2], nil
+	if err != nil {
+		return "", "", err
 	}
 
-	return "", "", errors.New("failed to match the value to the regex")
+	return stringutil.Unescape(segments[0]), contents, nil
 }
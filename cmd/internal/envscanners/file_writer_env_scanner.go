@@ -1,9 +1,11 @@
 package envscanners
 
 import (
+	"errors"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"github.com/rs/zerolog/log"
 	"strings"
@@ -15,6 +17,8 @@ type FileWriterEnvScanner struct {
 }
 
 func (f FileWriterEnvScanner) ProcessEnvVars() error {
+	unapplied := []*customerror.UdlError{}
+
 	for _, e := range f.Env.GetAllEnvVars() {
 
 		if i := strings.Index(e, "="); i >= 0 {
@@ -24,12 +28,17 @@ func (f FileWriterEnvScanner) ProcessEnvVars() error {
 			for _, p := range prefixes.EnvVarPrefixes {
 				prefix := p + "UDL_WRITEFILE["
 				if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, "]") {
-					file := strings.TrimLeft(strings.TrimRight(key, "]"), prefix)
+					file, err := f.getFilePath(key[len(prefix)-1:])
+
+					if err != nil {
+						unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+						continue
+					}
 
 					log.Debug().Msg("Writing file \"" + file + "\" with content:")
 					log.Debug().Msg(value)
 
-					err := f.Writer.WriteString(file, value)
+					err = f.Writer.WriteString(file, value)
 
 					if err != nil {
 						return &customerror.UdlError{
@@ -42,5 +51,20 @@ func (f FileWriterEnvScanner) ProcessEnvVars() error {
 		}
 	}
 
-	return nil
+	return unappliedError(unapplied)
+}
+
+// getFilePath returns the file name from the bracketed segment after the directive name.
+func (f FileWriterEnvScanner) getFilePath(key string) (string, error) {
+	segments, remaining, err := stringutil.BracketedSegments(key, 1)
+
+	if err != nil {
+		return "", err
+	}
+
+	if remaining != "" {
+		return "", errors.New("unexpected text \"" + remaining + "\" after the file name")
+	}
+
+	return stringutil.Unescape(segments[0]), nil
 }
@@ -1,6 +1,8 @@
 package envscanners
 
 import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"testing"
@@ -34,3 +36,57 @@ func TestFileWriting(t *testing.T) {
 		t.Fatal("Did not save the expected content")
 	}
 }
+
+func TestFileWritingEscapedBrackets(t *testing.T) {
+	jsonExample := "{\"whatever\":\"value\"}"
+	writer := writers.StringWriter{}
+	scanner := FileWriterEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_WRITEFILE[/etc/myapp/\\[1/settings.json]": jsonExample,
+			},
+		},
+		Writer: &writer,
+	}
+
+	err := scanner.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	value, ok := (*writer.Output)["/etc/myapp/[1/settings.json"]
+
+	if !ok {
+		t.Fatal("Did not create the expected file")
+	}
+
+	if value != jsonExample {
+		t.Fatal("Did not save the expected content")
+	}
+}
+
+func TestFileWritingMalformedKey(t *testing.T) {
+	writer := writers.StringWriter{}
+	scanner := FileWriterEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_WRITEFILE[/tmp/x[y]":                 "hi",
+				"UDL_WRITEFILE[/etc/myapp/settings.json]": "{}",
+			},
+		},
+		Writer: &writer,
+	}
+
+	err := scanner.ProcessEnvVars()
+
+	var unappliedError *customerror.UnappliedError
+	if !errors.As(err, &unappliedError) || len(unappliedError.Errors) != 1 ||
+		unappliedError.Errors[0].EnvVar != "UDL_WRITEFILE[/tmp/x[y]" {
+		t.Fatal("The malformed env var must be reported as not applied")
+	}
+
+	if _, ok := (*writer.Output)["/etc/myapp/settings.json"]; !ok {
+		t.Fatal("The other files must still be written")
+	}
+}
@@ -1,13 +1,12 @@
 package envscanners
 
 import (
-	"errors"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"github.com/rs/zerolog/log"
-	"regexp"
 	"strings"
 )
 
@@ -17,6 +16,8 @@ type FileWriterEnvScannerTwo struct {
 }
 
 func (f FileWriterEnvScannerTwo) ProcessEnvVars() error {
+	unapplied := []*customerror.UdlError{}
+
 	for _, e := range f.Env.GetAllEnvVars() {
 
 		if i := strings.Index(e, "="); i >= 0 {
@@ -28,7 +29,8 @@ func (f FileWriterEnvScannerTwo) ProcessEnvVars() error {
 					file, contents, err := f.getFilePath(value)
 
 					if err != nil {
-						return err
+						unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+						continue
 					}
 
 					log.Debug().Msg("Writing file \"" + file + "\" with content:")
@@ -47,16 +49,15 @@ func (f FileWriterEnvScannerTwo) ProcessEnvVars() error {
 		}
 	}
 
-	return nil
+	return unappliedError(unapplied)
 }
 
 func (f FileWriterEnvScannerTwo) getFilePath(key string) (string, string, error) {
-	rgx := regexp.MustCompile("\\[([^\\[\\]]+)](.*)")
-	rs := rgx.FindStringSubmatch(key)
+	segments, contents, err := stringutil.BracketedSegments(key, 1)
 
-	if rs != nil && len(rs) == 3 {
-		return rs[1], rs[2], nil
+	if err != nil {
+		return "", "", err
 	}
 
-	return "", "", errors.New("failed to match the value to the regex")
+	return stringutil.Unescape(segments[0]), contents, nil
 }
@@ -0,0 +1,126 @@
+package envscanners
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/rs/zerolog/log"
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+// getFormat returns the format assigned to a file by a directive like UDL_FORMAT[file]=toml, or the equivalent
+// UDL_FORMAT_CONFIG=[file]toml. An empty string is returned if the file has no assigned format.
+func getFormat(env envproviders.EnvironmentProvider, file string) string {
+	for _, e := range env.GetAllEnvVars() {
+		i := strings.Index(e, "=")
+		if i < 0 {
+			continue
+		}
+
+		key := e[:i]
+		value := e[i+1:]
+
+		for _, p := range prefixes.EnvVarPrefixes {
+			if match, _ := regexp.MatchString("^"+p+"UDL_FORMAT\\[.+]$", key); match {
+				segments, remaining, err := stringutil.BracketedSegments(key[strings.Index(key, "["):], 1)
+				if err == nil && remaining == "" && stringutil.Unescape(segments[0]) == file {
+					return strings.TrimSpace(value)
+				}
+			}
+
+			if match, _ := regexp.MatchString("^"+p+"UDL_FORMAT_[-._a-zA-Z0-9]+$", key); match {
+				segments, format, err := stringutil.BracketedSegments(value, 1)
+				if err == nil && stringutil.Unescape(segments[0]) == file {
+					return strings.TrimSpace(format)
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// findManipulator returns the manipulator used to modify a file. A format assigned with UDL_FORMAT takes precedence.
+// Otherwise, the first manipulator that matches the file name and can parse the file is used. Files whose names are
+// not recognised by any manipulator, like "app.conf" or "config", are matched by their content, with the manipulators
+// tried in the order they are listed. The returned error explains whether the file could not be read, could not be
+// parsed in the format matching its name, or did not match any format.
+func findManipulator(env envproviders.EnvironmentProvider, manipulatorList []manipulators.Manipulator, file string) (manipulators.Manipulator, error) {
+	if format := getFormat(env, file); format != "" {
+		formatNames := []string{}
+		for _, manipulator := range manipulatorList {
+			if strings.EqualFold(manipulator.GetFormatName(), format) {
+				log.Debug().Msg("Using the " + manipulator.GetFormatName() + " format assigned to " + file)
+				return manipulator, nil
+			}
+			formatNames = append(formatNames, manipulator.GetFormatName())
+		}
+
+		return nil, errors.New("the format \"" + format + "\" assigned to " + file + " must be one of " + strings.Join(formatNames, ", "))
+	}
+
+	var parseErr error
+	for _, manipulator := range manipulatorList {
+		if !manipulator.MatchesFileName(file) {
+			continue
+		}
+
+		log.Debug().Msg("Attempting to parse " + file + " as " + manipulator.GetFormatName())
+
+		err := manipulator.ParseError(file)
+		if err == nil {
+			log.Debug().Msg("Successfully parsed " + file + " as " + manipulator.GetFormatName())
+			return manipulator, nil
+		}
+
+		if readErr := readError(file, err); readErr != nil {
+			return nil, readErr
+		}
+
+		log.Debug().Msg("Could not parse " + file + " as " + manipulator.GetFormatName() + ": " + err.Error())
+
+		if parseErr == nil {
+			parseErr = fmt.Errorf("could not parse %s as %s: %w", file, manipulator.GetFormatName(), err)
+		}
+	}
+
+	// A file whose name belongs to a format, but which can not be parsed, is not guessed at, as it is more likely to
+	// be invalid than to be in some other format
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	for _, manipulator := range manipulatorList {
+		err := manipulator.ParseError(file)
+		if err == nil {
+			log.Debug().Msg("Detected the format of " + file + " as " + manipulator.GetFormatName())
+			return manipulator, nil
+		}
+
+		if readErr := readError(file, err); readErr != nil {
+			return nil, readErr
+		}
+	}
+
+	return nil, errors.New("no format matches the name of " + file + ", and its content could not be parsed as any format." +
+		" The format can be set with UDL_FORMAT[" + file + "]")
+}
+
+// readError returns an error if the file could not be read, or nil if the error is from parsing the file.
+func readError(file string, err error) error {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		return nil
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("the file %s does not exist: %w", file, err)
+	}
+
+	return fmt.Errorf("could not read %s: %w", file, err)
+}
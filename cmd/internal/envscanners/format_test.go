@@ -0,0 +1,164 @@
+package envscanners
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/jsonmanipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/xmlmanipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"io/fs"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func newFormatTestManipulators() ([]manipulators.Manipulator, *writers.StringWriter) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/myapp/config.json":   "{\"whatever\":\"hello\"}",
+			"/tmp/myapp/invalid.json":  "<whatever>hello</whatever>",
+			"/tmp/myapp/app.conf":      "<whatever>hello</whatever>",
+			"/tmp/myapp/settings":      "{\"whatever\":\"hello\"}",
+			"/tmp/myapp/unknown.conf":  "whatever",
+			"/tmp/myapp/app.json.tmpl": "{\"whatever\":\"hello\"}",
+		},
+	}
+
+	return []manipulators.Manipulator{
+		jsonmanipulators.JsonManipulator{
+			Reader: reader,
+			Writer: &writer,
+			MapManipulator: manipulators.CommonMapManipulator{
+				Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+			},
+		},
+		xmlmanipulators.XmlManipulator{
+			Reader: reader,
+			Writer: &writer,
+		},
+	}, &writer
+}
+
+func TestFindManipulator(t *testing.T) {
+	tests := []struct {
+		file   string
+		format string
+	}{
+		{"/tmp/myapp/config.json", "JSON"},
+		{"/tmp/myapp/app.conf", "XML"},
+		{"/tmp/myapp/settings", "JSON"},
+		{"/tmp/myapp/app.json.tmpl", "JSON"},
+	}
+
+	manipulatorList, _ := newFormatTestManipulators()
+
+	for _, test := range tests {
+		manipulator, err := findManipulator(envproviders.StringProvider{}, manipulatorList, test.file)
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if manipulator.GetFormatName() != test.format {
+			t.Fatal(test.file + " must be detected as " + test.format + " (was " + manipulator.GetFormatName() + ")")
+		}
+	}
+
+	failures := []struct {
+		file   string
+		reason string
+	}{
+		{"/tmp/myapp/invalid.json", "could not parse /tmp/myapp/invalid.json as JSON"},
+		{"/tmp/myapp/unknown.conf", "no format matches the name of /tmp/myapp/unknown.conf"},
+		{"/tmp/myapp/missing.conf", "the file /tmp/myapp/missing.conf does not exist"},
+		{"/tmp/myapp/missing.json", "the file /tmp/myapp/missing.json does not exist"},
+	}
+
+	for _, failure := range failures {
+		_, err := findManipulator(envproviders.StringProvider{}, manipulatorList, failure.file)
+
+		if err == nil {
+			t.Fatal(failure.file + " must not be matched to a format")
+		}
+
+		if !strings.HasPrefix(err.Error(), failure.reason) {
+			t.Fatal("The error for " + failure.file + " must start with \"" + failure.reason + "\" (was \"" + err.Error() + "\")")
+		}
+	}
+
+	_, err := findManipulator(envproviders.StringProvider{}, manipulatorList, "/tmp/myapp/missing.json")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("The error reading a missing file must be kept")
+	}
+}
+
+func TestFindManipulatorWithFormat(t *testing.T) {
+	tests := []struct {
+		env    map[string]string
+		format string
+	}{
+		{map[string]string{"UDL_FORMAT[/tmp/myapp/app.conf]": "json"}, "JSON"},
+		{map[string]string{"UDL_FORMAT_APP": "[/tmp/myapp/app.conf]Json"}, "JSON"},
+		{map[string]string{"APPSETTING_UDL_FORMAT_APP": "[/tmp/myapp/app.conf]xml"}, "XML"},
+		{map[string]string{"UDL_FORMAT[/tmp/myapp/other.conf]": "json"}, "XML"},
+	}
+
+	manipulatorList, _ := newFormatTestManipulators()
+
+	for _, test := range tests {
+		manipulator, err := findManipulator(envproviders.StringProvider{Vars: test.env}, manipulatorList, "/tmp/myapp/app.conf")
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if manipulator.GetFormatName() != test.format {
+			t.Fatal("The file must be processed as " + test.format + " (was " + manipulator.GetFormatName() + ")")
+		}
+	}
+
+	_, err := findManipulator(envproviders.StringProvider{Vars: map[string]string{"UDL_FORMAT[/tmp/myapp/app.conf]": "csv"}},
+		manipulatorList, "/tmp/myapp/app.conf")
+
+	if err == nil {
+		t.Fatal("Unknown formats must be rejected")
+	}
+}
+
+func TestUnknownFormatIsNotApplied(t *testing.T) {
+	manipulatorList, writer := newFormatTestManipulators()
+	manipulator := ManipulatorEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_SETVALUE[/tmp/myapp/unknown.conf][whatever]": "world",
+				"UDL_SETVALUE[/tmp/myapp/missing.json][whatever]": "world",
+			},
+		},
+		Manipulator: manipulatorList,
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	var unappliedError *customerror.UnappliedError
+	if !errors.As(err, &unappliedError) {
+		t.Fatal("The env vars that were not applied must be reported")
+	}
+
+	envVars := []string{}
+	for _, unapplied := range unappliedError.Errors {
+		envVars = append(envVars, unapplied.EnvVar)
+	}
+	sort.Strings(envVars)
+
+	if strings.Join(envVars, ",") != "UDL_SETVALUE[/tmp/myapp/missing.json][whatever],UDL_SETVALUE[/tmp/myapp/unknown.conf][whatever]" {
+		t.Fatal("Each env var that was not applied must be reported (was " + strings.Join(envVars, ",") + ")")
+	}
+
+	if writer.Output != nil {
+		t.Fatal("Files with an unknown format must not be modified")
+	}
+}
@@ -0,0 +1,164 @@
+package envscanners
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/rs/zerolog/log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ManipulatorDeleteEnvScanner removes values from config files. The value assigned to the env var is ignored.
+type ManipulatorDeleteEnvScanner struct {
+	Env         envproviders.EnvironmentProvider
+	Manipulator []manipulators.Manipulator
+}
+
+func (f ManipulatorDeleteEnvScanner) getFilePath(key string) (string, string, error) {
+	segments, remaining, err := stringutil.BracketedSegments(key[strings.Index(key, "["):], 2)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if remaining != "" {
+		return "", "", errors.New("unexpected text \"" + remaining + "\" after the path")
+	}
+
+	return stringutil.Unescape(segments[0]), segments[1], nil
+}
+
+func (f ManipulatorDeleteEnvScanner) getVars() ([]int, map[int][]string) {
+	orderedVars := map[int][]string{}
+	orderedVarsKeys := []int{}
+
+	// start by getting matching env vars, splitting the values by colon, and saving
+	// the env var in a map keyed by the length of the accessor
+	for _, e := range f.Env.GetAllEnvVars() {
+
+		if i := strings.Index(e, "="); i >= 0 {
+			key := e[:i]
+
+			for _, p := range prefixes.EnvVarPrefixes {
+				if !strings.HasPrefix(key, p+"UDL_DELETEVALUE[") {
+					continue
+				}
+
+				_, path, err := f.getFilePath(key)
+
+				// Env vars that can not be parsed are kept, so the error is reported when they are processed
+				length := 0
+				if err == nil {
+					length = len(manipulators.SplitPath(path))
+				}
+
+				if _, ok := orderedVars[length]; !ok {
+					orderedVars[length] = []string{}
+					orderedVarsKeys = append(orderedVarsKeys, length)
+				}
+
+				orderedVars[length] = append(orderedVars[length], key)
+			}
+		}
+	}
+
+	// Sort the array of accessor lengths from longest to shortest
+	sort.Sort(sort.Reverse(sort.IntSlice(orderedVarsKeys)))
+
+	for _, length := range orderedVarsKeys {
+		sortDeletes(orderedVars[length], f.getFilePath)
+	}
+
+	return orderedVarsKeys, orderedVars
+}
+
+// sortDeletes orders env vars that delete values at paths of the same length. Items deleted from the same array are
+// deleted from the highest index to the lowest, so deleting an item does not change the index of the items deleted
+// after it. Negative indexes count back from the end of the array, and so are deleted in the order of their env var
+// names like any other key.
+func sortDeletes(keys []string, getFilePath func(key string) (string, string, error)) {
+	type deleteTarget struct {
+		key    string
+		file   string
+		parent string
+		index  int
+	}
+
+	targets := []deleteTarget{}
+	for _, key := range keys {
+		target := deleteTarget{key: key, index: -1}
+
+		if file, path, err := getFilePath(key); err == nil && len(manipulators.SplitPath(path)) != 0 {
+			splitPath := manipulators.SplitPath(path)
+			target.file = file
+			target.parent = strings.Join(splitPath[:len(splitPath)-1], ":")
+
+			if index, err := strconv.Atoi(splitPath[len(splitPath)-1]); err == nil && index >= 0 {
+				target.index = index
+			}
+		}
+
+		targets = append(targets, target)
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].file != targets[j].file {
+			return targets[i].file < targets[j].file
+		}
+
+		if targets[i].parent != targets[j].parent {
+			return targets[i].parent < targets[j].parent
+		}
+
+		if targets[i].index != targets[j].index {
+			return targets[i].index > targets[j].index
+		}
+
+		return targets[i].key < targets[j].key
+	})
+
+	for i, target := range targets {
+		keys[i] = target.key
+	}
+}
+
+func (f ManipulatorDeleteEnvScanner) ProcessEnvVars() error {
+	orderedVarsKeys, orderedVars := f.getVars()
+	unapplied := []*customerror.UdlError{}
+
+	// Starting with the accessors of the longest length, process the deletions.
+	// This means that deeper properties are removed before the properties that
+	// contain them.
+	for _, length := range orderedVarsKeys {
+		for _, key := range orderedVars[length] {
+			file, path, err := f.getFilePath(key)
+
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
+
+			manipulator, err := findManipulator(f.Env, f.Manipulator, file)
+
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
+
+			log.Debug().Msg("Parsed " + file + " as " + manipulator.GetFormatName() + " to delete value at " + path)
+
+			err = manipulator.DeleteValue(file, path)
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
+		}
+	}
+
+	return unappliedError(unapplied)
+}
@@ -0,0 +1,143 @@
+package envscanners
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/jsonmanipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+	"testing"
+)
+
+func TestJsonDelete(t *testing.T) {
+	jsonExample := "{\"whatever\":\"hello\",\"db\":{\"host\":\"localhost\"}}"
+	files := map[string]string{
+		"/tmp/myapp/config.json": jsonExample,
+	}
+
+	writer := writers.StringWriter{
+		Output: &files,
+	}
+	reader := readers.StringReader{
+		Files: &files,
+	}
+	manipulator := ManipulatorDeleteEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_DELETEVALUE[/tmp/myapp/config.json][whatever]": "",
+				"UDL_DELETEVALUE[/tmp/myapp/config.json][db]":       "",
+				"UDL_DELETEVALUE[/tmp/myapp/config.json][db:host]":  "",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte(files["/tmp/myapp/config.json"]), &result)
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(result) != 0 {
+		t.Fatal("all values must have been deleted")
+	}
+}
+
+func TestDeleteOrder(t *testing.T) {
+	scanner := ManipulatorDeleteEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_DELETEVALUE[/tmp/myapp/config.json][items:0]":            "",
+				"APPSETTING_UDL_DELETEVALUE[/tmp/myapp/config.json][items:2]": "",
+				"UDL_DELETEVALUE[/tmp/myapp/config.json][items:1]":            "",
+				"UDL_DELETEVALUE[/tmp/myapp/config.json][other:0]":            "",
+			},
+		},
+	}
+
+	orderedVarsKeys, orderedVars := scanner.getVars()
+	keys := strings.Join(orderedVars[orderedVarsKeys[0]], ",")
+
+	// Each env var is deleted once, and indexes of the same array are deleted from the highest to the lowest
+	if keys != "APPSETTING_UDL_DELETEVALUE[/tmp/myapp/config.json][items:2],"+
+		"UDL_DELETEVALUE[/tmp/myapp/config.json][items:1],"+
+		"UDL_DELETEVALUE[/tmp/myapp/config.json][items:0],"+
+		"UDL_DELETEVALUE[/tmp/myapp/config.json][other:0]" {
+		t.Fatal("The array items must be deleted once from the highest index to the lowest (was " + keys + ")")
+	}
+}
+
+func TestDeleteOrderTwo(t *testing.T) {
+	scanner := ManipulatorDeleteEnvScannerTwo{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_DELETEVALUE_A":            "[/tmp/myapp/config.json][items:0]",
+				"APPSETTING_UDL_DELETEVALUE_B": "[/tmp/myapp/config.json][items:10]",
+				"UDL_DELETEVALUE_C":            "[/tmp/myapp/config.json][items:9]",
+			},
+		},
+	}
+
+	orderedVarsKeys, orderedVars := scanner.getVars()
+	keys := strings.Join(orderedVars[orderedVarsKeys[0]], ",")
+
+	if keys != "APPSETTING_UDL_DELETEVALUE_B,UDL_DELETEVALUE_C,UDL_DELETEVALUE_A" {
+		t.Fatal("The array items must be deleted once from the highest index to the lowest (was " + keys + ")")
+	}
+}
+
+func TestDeleteTwoTrailingText(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/myapp/config.json": "{\"a\":1,\"b\":2}",
+		},
+	}
+	scanner := ManipulatorDeleteEnvScannerTwo{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_DELETEVALUE_Y": "[/tmp/myapp/config.json][a]junk",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := scanner.ProcessEnvVars()
+
+	var unappliedError *customerror.UnappliedError
+	if !errors.As(err, &unappliedError) || len(unappliedError.Errors) != 1 ||
+		!strings.HasPrefix(unappliedError.Errors[0].Error(), "unexpected text \"junk\"") {
+		t.Fatal("Text after the path must be reported as not applied")
+	}
+
+	if writer.Output != nil {
+		t.Fatal("The file must not be modified")
+	}
+}
@@ -0,0 +1,121 @@
+package envscanners
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/rs/zerolog/log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ManipulatorDeleteEnvScannerTwo exists because even though it is legal to have brackets in env var names in Linux,
+// it is not legal to have them in Kubernetes. This manipulator uses plain env var names and defines the
+// filename and accessor in the env var value.
+type ManipulatorDeleteEnvScannerTwo struct {
+	Env         envproviders.EnvironmentProvider
+	Manipulator []manipulators.Manipulator
+}
+
+func (f ManipulatorDeleteEnvScannerTwo) getFilePath(key string) (string, string, error) {
+	segments, remaining, err := stringutil.BracketedSegments(key, 2)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if remaining != "" {
+		return "", "", errors.New("unexpected text \"" + remaining + "\" after the path")
+	}
+
+	return stringutil.Unescape(segments[0]), segments[1], nil
+}
+
+func (f ManipulatorDeleteEnvScannerTwo) getVars() ([]int, map[int][]string) {
+	orderedVars := map[int][]string{}
+	orderedVarsKeys := []int{}
+
+	// start by getting matching env vars, splitting the values by colon, and saving
+	// the env var in a map keyed by the length of the accessor
+	for _, e := range f.Env.GetAllEnvVars() {
+
+		if i := strings.Index(e, "="); i >= 0 {
+			key := e[:i]
+			value := e[i+1:]
+
+			for _, p := range prefixes.EnvVarPrefixes {
+
+				if match, _ := regexp.MatchString("^"+p+"UDL_DELETEVALUE_[-._a-zA-Z0-9]+$", key); !match {
+					continue
+				}
+
+				_, accessor, err := f.getFilePath(value)
+
+				// Env vars that can not be parsed are kept, so the error is reported when they are processed
+				length := 0
+				if err == nil {
+					length = len(manipulators.SplitPath(accessor))
+				}
+
+				if _, ok := orderedVars[length]; !ok {
+					orderedVars[length] = []string{}
+					orderedVarsKeys = append(orderedVarsKeys, length)
+				}
+
+				orderedVars[length] = append(orderedVars[length], key)
+			}
+		}
+	}
+
+	// Sort the array of accessor lengths from longest to shortest
+	sort.Sort(sort.Reverse(sort.IntSlice(orderedVarsKeys)))
+
+	for _, length := range orderedVarsKeys {
+		sortDeletes(orderedVars[length], func(key string) (string, string, error) {
+			return f.getFilePath(f.Env.GetEnvVar(key))
+		})
+	}
+
+	return orderedVarsKeys, orderedVars
+}
+
+func (f ManipulatorDeleteEnvScannerTwo) ProcessEnvVars() error {
+	orderedVarsKeys, orderedVars := f.getVars()
+	unapplied := []*customerror.UdlError{}
+
+	// Starting with the accessors of the longest length, process the deletions.
+	// This means that deeper properties are removed before the properties that
+	// contain them.
+	for _, length := range orderedVarsKeys {
+		for _, key := range orderedVars[length] {
+			value := f.Env.GetEnvVar(key)
+			file, accessor, err := f.getFilePath(value)
+
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
+
+			manipulator, err := findManipulator(f.Env, f.Manipulator, file)
+
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
+
+			log.Debug().Msg("Parsed " + file + " as " + manipulator.GetFormatName() + " to delete value at " + accessor)
+
+			err = manipulator.DeleteValue(file, accessor)
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
+		}
+	}
+
+	return unappliedError(unapplied)
+}
@@ -0,0 +1,73 @@
+package envscanners
+
+import (
+	"encoding/json"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/jsonmanipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"testing"
+)
+
+func TestJsonDeleteTwo(t *testing.T) {
+	jsonExample := "{\"whatever\":\"hello\",\"db\":{\"host\":\"localhost\",\"port\":5432}}"
+	files := map[string]string{
+		"/tmp/myapp/config.json": jsonExample,
+	}
+
+	writer := writers.StringWriter{
+		Output: &files,
+	}
+	reader := readers.StringReader{
+		Files: &files,
+	}
+	manipulator := ManipulatorDeleteEnvScannerTwo{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_DELETEVALUE_1": "[/tmp/myapp/config.json][whatever]",
+				"UDL_DELETEVALUE_2": "[/tmp/myapp/config.json][db:host]",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte(files["/tmp/myapp/config.json"]), &result)
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if _, ok := result["whatever"]; ok {
+		t.Fatal("whatever must have been deleted")
+	}
+
+	db, ok := result["db"].(map[string]any)
+
+	if !ok {
+		t.Fatal("db must be retained")
+	}
+
+	if _, ok := db["host"]; ok {
+		t.Fatal("host must have been deleted")
+	}
+
+	if _, ok := db["port"]; !ok {
+		t.Fatal("port must be retained")
+	}
+}
@@ -1,12 +1,12 @@
 package envscanners
 
 import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
 	"github.com/rs/zerolog/log"
-	"regexp"
 	"sort"
 	"strings"
 )
@@ -16,10 +16,20 @@ type ManipulatorEnvScanner struct {
 	Manipulator []manipulators.Manipulator
 }
 
-func (f ManipulatorEnvScanner) getFilePath(key string) (string, string) {
-	file := stringutil.Substr(key, len("UDL_SETVALUE["), strings.Index(key, "]")-len("UDL_SETVALUE["))
-	path := stringutil.Substr(key, strings.Index(key, "][")+2, len(key)-strings.Index(key, "][")-3)
-	return file, path
+func (f ManipulatorEnvScanner) getFilePath(key string) (string, string, manipulators.ValueType, error) {
+	segments, remaining, err := stringutil.BracketedSegments(key[strings.Index(key, "["):], 2)
+
+	if err != nil {
+		return "", "", manipulators.ValueTypeAuto, err
+	}
+
+	valueType, err := parseTypeHint(remaining)
+
+	if err != nil {
+		return "", "", manipulators.ValueTypeAuto, err
+	}
+
+	return stringutil.Unescape(segments[0]), segments[1], valueType, nil
 }
 
 func (f ManipulatorEnvScanner) getVars() ([]int, map[int][]string) {
@@ -34,18 +44,24 @@ func (f ManipulatorEnvScanner) getVars() ([]int, map[int][]string) {
 			key := e[:i]
 
 			for _, p := range prefixes.EnvVarPrefixes {
-				match, _ := regexp.MatchString(p+"UDL_SETVALUE\\[[^\\[\\]]+]\\[[^\\[\\]]+]", key)
+				if !strings.HasPrefix(key, p+"UDL_SETVALUE[") {
+					continue
+				}
 
-				if match {
-					_, path := f.getFilePath(key)
-					splitValue := strings.Split(path, ":")
-					if _, ok := orderedVars[len(splitValue)]; !ok {
-						orderedVars[len(splitValue)] = []string{}
-						orderedVarsKeys = append(orderedVarsKeys, len(splitValue))
-					}
+				_, path, _, err := f.getFilePath(key)
 
-					orderedVars[len(splitValue)] = append(orderedVars[len(splitValue)], key)
+				// Env vars that can not be parsed are kept, so the error is reported when they are processed
+				length := 0
+				if err == nil {
+					length = len(manipulators.SplitPath(path))
 				}
+
+				if _, ok := orderedVars[length]; !ok {
+					orderedVars[length] = []string{}
+					orderedVarsKeys = append(orderedVarsKeys, length)
+				}
+
+				orderedVars[length] = append(orderedVars[length], key)
 			}
 		}
 	}
@@ -58,6 +74,7 @@ func (f ManipulatorEnvScanner) getVars() ([]int, map[int][]string) {
 
 func (f ManipulatorEnvScanner) ProcessEnvVars() error {
 	orderedVarsKeys, orderedVars := f.getVars()
+	unapplied := []*customerror.UdlError{}
 
 	// Starting with the accessors of the shortest length, process to injections.
 	// This means that top level properties are set first, and deeper properties
@@ -66,25 +83,29 @@ func (f ManipulatorEnvScanner) ProcessEnvVars() error {
 	for _, length := range orderedVarsKeys {
 		for _, key := range orderedVars[length] {
 			value := f.Env.GetEnvVar(key)
-			file, path := f.getFilePath(key)
+			file, path, valueType, err := f.getFilePath(key)
 
-			for _, manipulator := range f.Manipulator {
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
 
-				log.Debug().Msg("Attempting to parse " + file + " as " + manipulator.GetFormatName() + " and modify value at " + path)
+			manipulator, err := findManipulator(f.Env, f.Manipulator, file)
 
-				if manipulator.CanManipulate(file) {
-					log.Debug().Msg("Successfully parsed " + file + " as " + manipulator.GetFormatName())
-					err := manipulator.SetValue(file, path, value)
-					if err != nil {
-						return err
-					}
-					break
-				} else {
-					log.Debug().Msg("Could not parse " + file + " as " + manipulator.GetFormatName())
-				}
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
+
+			log.Debug().Msg("Parsed " + file + " as " + manipulator.GetFormatName() + " to modify value at " + path)
+
+			err = manipulator.SetTypedValue(file, path, value, valueType)
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
 			}
 		}
 	}
 
-	return nil
+	return unappliedError(unapplied)
 }
@@ -2,12 +2,15 @@ package envscanners
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/jsonmanipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
 	"testing"
 )
 
@@ -134,3 +137,199 @@ func TestMultipleJsonManipulation(t *testing.T) {
 		t.Fatal("first item must be set to 8")
 	}
 }
+
+func TestJsonManipulationTypeHint(t *testing.T) {
+	jsonExample := "{\"whatever\":\"hello\"}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/myapp/config.json": jsonExample,
+		},
+	}
+	manipulator := ManipulatorEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_SETVALUE[/tmp/myapp/config.json][whatever][int]": "8080",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := (*writer.Output)["/tmp/myapp/config.json"]
+
+	if output != "{\"whatever\":8080}" {
+		t.Fatal("value must be set to the number 8080 (was: \"" + output + "\")")
+	}
+}
+
+func TestJsonManipulationEscapedPath(t *testing.T) {
+	jsonExample := "{\"urls:http\":\"hello\"}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/my]app/config.json": jsonExample,
+		},
+	}
+	manipulator := ManipulatorEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_SETVALUE[/tmp/my\\]app/config.json][urls\\:http]": "world",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := (*writer.Output)["/tmp/my]app/config.json"]
+
+	if output != "{\"urls:http\":\"world\"}" {
+		t.Fatal("value must be set to \"world\" (was: \"" + output + "\")")
+	}
+}
+
+func TestJsonManipulationFailureIsNotApplied(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/myapp/config.json": "{\"whatever\":\"hello\",\"items\":[1]}",
+		},
+	}
+	manipulator := ManipulatorEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_SETVALUE[/tmp/myapp/config.json][items:5]":       "2",
+				"UDL_SETVALUE[/tmp/myapp/config.json][whatever][int]": "world",
+				"UDL_SETVALUE[/tmp/myapp/config.json][whatever]":      "world",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	var unappliedError *customerror.UnappliedError
+	if !errors.As(err, &unappliedError) || len(unappliedError.Errors) != 2 {
+		t.Fatal("The values that could not be set must be reported as not applied")
+	}
+
+	for _, unapplied := range unappliedError.Errors {
+		if unapplied.EnvVar != "UDL_SETVALUE[/tmp/myapp/config.json][items:5]" &&
+			unapplied.EnvVar != "UDL_SETVALUE[/tmp/myapp/config.json][whatever][int]" {
+			t.Fatal("The error must name the env var that was not applied (was " + unapplied.EnvVar + ")")
+		}
+	}
+
+	if _, ok := (*writer.Output)["/tmp/myapp/config.json"]; !ok {
+		t.Fatal("The other values must still be set")
+	}
+}
+
+func countVars(orderedVarsKeys []int, orderedVars map[int][]string) int {
+	count := 0
+	for _, length := range orderedVarsKeys {
+		count += len(orderedVars[length])
+	}
+	return count
+}
+
+func TestPrefixedVarsAreMatchedOnce(t *testing.T) {
+	env := envproviders.StringProvider{
+		Vars: map[string]string{
+			"APPSETTING_UDL_SETVALUE[/tmp/myapp/config.json][items:-]":           "2",
+			"APPSETTING_UDL_SETVALUE_ITEMS":                                      "[/tmp/myapp/config.json][items:-]2",
+			"APPSETTING_UDL_SKIPEMPTY_SETVALUE[/tmp/myapp/config.json][items:-]": "2",
+			"APPSETTING_UDL_SKIPEMPTY_SETVALUE_ITEMS":                            "[/tmp/myapp/config.json][items:-]2",
+		},
+	}
+
+	counts := map[string]int{
+		"ManipulatorEnvScanner":             countVars(ManipulatorEnvScanner{Env: env}.getVars()),
+		"ManipulatorEnvScannerTwo":          countVars(ManipulatorEnvScannerTwo{Env: env}.getVars()),
+		"ManipulatorSkipEmptyEnvScanner":    countVars(ManipulatorSkipEmptyEnvScanner{Env: env}.getVars()),
+		"ManipulatorSkipEmptyEnvScannerTwo": countVars(ManipulatorSkipEmptyEnvScannerTwo{Env: env}.getVars()),
+	}
+
+	for scanner, count := range counts {
+		if count != 1 {
+			t.Fatal(scanner + " must match a prefixed env var once (matched " + fmt.Sprint(count) + " times)")
+		}
+	}
+}
+
+func TestJsonManipulationUnknownTypeHint(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/myapp/config.json": "{\"port\":80}",
+		},
+	}
+	manipulator := ManipulatorEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_SETVALUE[/tmp/myapp/config.json][port][integer]": "8080",
+				"UDL_SETVALUE[/tmp/myapp/config.json][port][Int]":     "8080",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	var unappliedError *customerror.UnappliedError
+	if !errors.As(err, &unappliedError) || len(unappliedError.Errors) != 2 {
+		t.Fatal("Env vars with an unknown type hint must be reported as not applied")
+	}
+
+	for _, unapplied := range unappliedError.Errors {
+		if !strings.HasPrefix(unapplied.Error(), "unknown type hint") {
+			t.Fatal("The error must explain that the type hint is unknown (was " + unapplied.Error() + ")")
+		}
+	}
+
+	if writer.Output != nil {
+		t.Fatal("The file must not be modified")
+	}
+}
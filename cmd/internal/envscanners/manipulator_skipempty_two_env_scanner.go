@@ -1,10 +1,11 @@
 package envscanners
 
 import (
-	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
 	"github.com/rs/zerolog/log"
 	"regexp"
 	"sort"
@@ -20,14 +21,13 @@ type ManipulatorSkipEmptyEnvScannerTwo struct {
 }
 
 func (f ManipulatorSkipEmptyEnvScannerTwo) getFilePath(key string) (string, string, string, error) {
-	rgx := regexp.MustCompile("\\[([^\\[\\]]+)]\\[([^\\[\\]]+)](.*)")
-	rs := rgx.FindStringSubmatch(key)
+	segments, value, err := stringutil.BracketedSegments(key, 2)
 
-	if rs != nil && len(rs) == 4 {
-		return rs[1], rs[2], rs[3], nil
+	if err != nil {
+		return "", "", "", err
 	}
 
-	return "", "", "", errors.New("failed to match the value to the regex")
+	return stringutil.Unescape(segments[0]), segments[1], value, nil
 }
 
 func (f ManipulatorSkipEmptyEnvScannerTwo) getVars() ([]int, map[int][]string) {
@@ -40,25 +40,28 @@ func (f ManipulatorSkipEmptyEnvScannerTwo) getVars() ([]int, map[int][]string) {
 
 		if i := strings.Index(e, "="); i >= 0 {
 			key := e[:i]
-			value := e[i-1:]
+			value := e[i+1:]
 
 			for _, p := range prefixes.EnvVarPrefixes {
 
-				if match, _ := regexp.MatchString(p+"UDL_SKIPEMPTY_SETVALUE_[-._a-zA-Z0-9]+", key); !match {
+				if match, _ := regexp.MatchString("^"+p+"UDL_SKIPEMPTY_SETVALUE_[-._a-zA-Z0-9]+$", key); !match {
 					continue
 				}
 
 				_, accessor, _, err := f.getFilePath(value)
 
+				// Env vars that can not be parsed are kept, so the error is reported when they are processed
+				length := 0
 				if err == nil {
-					splitPath := strings.Split(accessor, ":")
-					if _, ok := orderedVars[len(splitPath)]; !ok {
-						orderedVars[len(splitPath)] = []string{}
-						orderedVarsKeys = append(orderedVarsKeys, len(splitPath))
-					}
+					length = len(manipulators.SplitPath(accessor))
+				}
 
-					orderedVars[len(splitPath)] = append(orderedVars[len(splitPath)], key)
+				if _, ok := orderedVars[length]; !ok {
+					orderedVars[length] = []string{}
+					orderedVarsKeys = append(orderedVarsKeys, length)
 				}
+
+				orderedVars[length] = append(orderedVars[length], key)
 			}
 		}
 	}
@@ -71,6 +74,7 @@ func (f ManipulatorSkipEmptyEnvScannerTwo) getVars() ([]int, map[int][]string) {
 
 func (f ManipulatorSkipEmptyEnvScannerTwo) ProcessEnvVars() error {
 	orderedVarsKeys, orderedVars := f.getVars()
+	unapplied := []*customerror.UdlError{}
 
 	// Starting with the accessors of the shortest length, process to injections.
 	// This means that top level properties are set first, and deeper properties
@@ -81,33 +85,33 @@ func (f ManipulatorSkipEmptyEnvScannerTwo) ProcessEnvVars() error {
 			value := f.Env.GetEnvVar(key)
 
 			file, accessor, newValue, err := f.getFilePath(value)
+			newValue, valueType := trimTypeHint(newValue)
 
 			if len(strings.TrimSpace(newValue)) == 0 {
 				continue
 			}
 
 			if err != nil {
-				log.Debug().Msg("Could not parse " + value + " as a manipulation directive")
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
 				continue
 			}
 
-			for _, manipulator := range f.Manipulator {
+			manipulator, err := findManipulator(f.Env, f.Manipulator, file)
 
-				log.Debug().Msg("Attempting to parse " + file + " as " + manipulator.GetFormatName() + " and modify value at " + accessor)
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
+			}
 
-				if manipulator.CanManipulate(file) {
-					log.Debug().Msg("Successfully parsed " + file + " as " + manipulator.GetFormatName())
-					err := manipulator.SetValue(file, accessor, newValue)
-					if err != nil {
-						return err
-					}
-					break
-				} else {
-					log.Debug().Msg("Could not parse " + file + " as " + manipulator.GetFormatName())
-				}
+			log.Debug().Msg("Parsed " + file + " as " + manipulator.GetFormatName() + " to modify value at " + accessor)
+
+			err = manipulator.SetTypedValue(file, accessor, newValue, valueType)
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+				continue
 			}
 		}
 	}
 
-	return nil
+	return unappliedError(unapplied)
 }
@@ -6,8 +6,10 @@ import (
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/jsonmanipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/xmlmanipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
 	"testing"
 )
 
@@ -134,3 +136,119 @@ func TestMultipleJsonManipulationTwo(t *testing.T) {
 		t.Fatal("first item must be set to 8")
 	}
 }
+
+// TestXmlManipulationTwo verifies that accessors can contain nested brackets, like XPath filters
+func TestXmlManipulationTwo(t *testing.T) {
+	xmlExample := "<configuration><add key=\"Db\" value=\"localhost\"/></configuration>"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/myapp/web.config": xmlExample,
+		},
+	}
+	manipulator := ManipulatorEnvScannerTwo{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_SETVALUE_DB": "[/tmp/myapp/web.config][/configuration/add[@key='Db']/@value]db.example.org",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			xmlmanipulators.XmlManipulator{
+				Reader: reader,
+				Writer: &writer,
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	value, ok := (*writer.Output)["/tmp/myapp/web.config"]
+
+	if !ok {
+		t.Fatal("Did not create the expected file")
+	}
+
+	if !strings.Contains(value, "value=\"db.example.org\"") {
+		t.Fatal("value must be set to \"db.example.org\", file was " + value)
+	}
+}
+
+func TestJsonManipulationTwoTypeHint(t *testing.T) {
+	jsonExample := "{\"whatever\":\"hello\"}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/myapp/config.json": jsonExample,
+		},
+	}
+	manipulator := ManipulatorEnvScannerTwo{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_SETVALUE_WHATEVER": "[/tmp/myapp/config.json][whatever][bool]true",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := (*writer.Output)["/tmp/myapp/config.json"]
+
+	if output != "{\"whatever\":true}" {
+		t.Fatal("value must be set to the boolean true (was: \"" + output + "\")")
+	}
+}
+
+func TestJsonManipulationTwoEscapedPath(t *testing.T) {
+	jsonExample := "{\"hosts\":{\"fe80::1\":\"hello\"}}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/tmp/myapp/config.json": jsonExample,
+		},
+	}
+	manipulator := ManipulatorEnvScannerTwo{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_SETVALUE_HOST": "[/tmp/myapp/config.json][hosts:fe80\\:\\:1]world",
+			},
+		},
+		Manipulator: []manipulators.Manipulator{
+			jsonmanipulators.JsonManipulator{
+				Reader: reader,
+				Writer: &writer,
+				MapManipulator: manipulators.CommonMapManipulator{
+					Unmarshaller: jsonmanipulators.JsonUnmarshaller{},
+				},
+			},
+		},
+	}
+
+	err := manipulator.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := (*writer.Output)["/tmp/myapp/config.json"]
+
+	if output != "{\"hosts\":{\"fe80::1\":\"world\"}}" {
+		t.Fatal("value must be set to \"world\" (was: \"" + output + "\")")
+	}
+}
@@ -0,0 +1,151 @@
+package envscanners
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"github.com/rs/zerolog/log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	replaceModeLiteral = "literal"
+	replaceModeRegex   = "regex"
+)
+
+// replacement is the text replacement defined by a directive like UDL_REPLACE[file][pattern].
+type replacement struct {
+	envVar  string
+	pattern string
+	value   string
+	regex   bool
+}
+
+// newReplacement creates a replacement from the pattern segment of a directive. Literal patterns are unescaped like
+// file names. Regular expressions are used as is, because "\[", "\]", and "\\" have the same meaning in a regular
+// expression as they do in a bracketed segment.
+func newReplacement(envVar string, pattern string, value string, regex bool) replacement {
+	if !regex {
+		pattern = stringutil.Unescape(pattern)
+	}
+
+	return replacement{
+		envVar:  envVar,
+		pattern: pattern,
+		value:   value,
+		regex:   regex,
+	}
+}
+
+// apply replaces every match of the pattern in the content, returning the new content and the number of matches.
+// Regular expression replacements can reference capture groups like "$1" or "${name}".
+func (r replacement) apply(content string) (string, int, error) {
+	if !r.regex {
+		return strings.ReplaceAll(content, r.pattern, r.value), strings.Count(content, r.pattern), nil
+	}
+
+	pattern, err := regexp.Compile(r.pattern)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return pattern.ReplaceAllString(content, r.value), len(pattern.FindAllStringIndex(content, -1)), nil
+}
+
+// parseReplaceMode parses the optional mode that follows the pattern of a directive like
+// UDL_REPLACE[file][pattern][regex], returning true for regular expressions.
+func parseReplaceMode(remaining string) (bool, error) {
+	if remaining == "" {
+		return false, nil
+	}
+
+	segments, rest, err := stringutil.BracketedSegments(remaining, 1)
+	if err == nil && rest == "" {
+		switch strings.ToLower(segments[0]) {
+		case replaceModeLiteral:
+			return false, nil
+		case replaceModeRegex:
+			return true, nil
+		}
+	}
+
+	return false, errors.New("unexpected text \"" + remaining + "\" after the pattern")
+}
+
+// trimReplaceMode removes the mode from the start of the value of a directive like
+// UDL_REPLACE_URL=[file][pattern][regex]replacement. Values that do not start with a mode are returned unchanged.
+func trimReplaceMode(value string) (string, bool) {
+	lowerValue := strings.ToLower(value)
+
+	if strings.HasPrefix(lowerValue, "["+replaceModeRegex+"]") {
+		return value[len(replaceModeRegex)+2:], true
+	}
+
+	if strings.HasPrefix(lowerValue, "["+replaceModeLiteral+"]") {
+		return value[len(replaceModeLiteral)+2:], false
+	}
+
+	return value, false
+}
+
+// replaceInFiles applies the replacements to each file, returning the replacements that could not be applied. Each file
+// is read and written once, with the replacements applied in the order of the env var names. Patterns that do not match
+// are not an error, as the text has usually been replaced by an earlier start of the container.
+func replaceInFiles(reader readers.Reader, writer writers.Writer, replacements map[string][]replacement) ([]*customerror.UdlError, error) {
+	unapplied := []*customerror.UdlError{}
+
+	files := []string{}
+	for file := range replacements {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		content, err := reader.ReadString(file)
+
+		if err != nil {
+			for _, r := range replacements[file] {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: r.envVar, Err: err})
+			}
+			continue
+		}
+
+		fileReplacements := replacements[file]
+		sort.Slice(fileReplacements, func(i, j int) bool {
+			return fileReplacements[i].envVar < fileReplacements[j].envVar
+		})
+
+		newContent := content
+		for _, r := range fileReplacements {
+			replaced, count, err := r.apply(newContent)
+
+			if err != nil {
+				unapplied = append(unapplied, &customerror.UdlError{EnvVar: r.envVar, Err: err})
+				continue
+			}
+
+			log.Debug().Msg("Replaced " + strconv.Itoa(count) + " matches of \"" + r.pattern + "\" in " + file)
+			newContent = replaced
+		}
+
+		if newContent == content {
+			continue
+		}
+
+		err = writer.WriteString(file, newContent)
+
+		if err != nil {
+			return nil, &customerror.UdlError{
+				EnvVar: fileReplacements[0].envVar,
+				Err:    err,
+			}
+		}
+	}
+
+	return unapplied, nil
+}
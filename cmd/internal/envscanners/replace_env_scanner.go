@@ -0,0 +1,72 @@
+package envscanners
+
+import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+)
+
+// ReplaceEnvScanner replaces text in files of any format, using env vars like UDL_REPLACE[file][pattern] with the
+// replacement text as the value.
+type ReplaceEnvScanner struct {
+	Env    envproviders.EnvironmentProvider
+	Reader readers.Reader
+	Writer writers.Writer
+}
+
+func (f ReplaceEnvScanner) ProcessEnvVars() error {
+	replacements := map[string][]replacement{}
+	unapplied := []*customerror.UdlError{}
+
+	for _, e := range f.Env.GetAllEnvVars() {
+
+		if i := strings.Index(e, "="); i >= 0 {
+			key := e[:i]
+			value := e[i+1:]
+
+			for _, p := range prefixes.EnvVarPrefixes {
+				prefix := p + "UDL_REPLACE["
+				if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, "]") {
+					file, pattern, regex, err := f.getFilePattern(key[len(prefix)-1:])
+
+					if err != nil {
+						unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+						continue
+					}
+
+					replacements[file] = append(replacements[file], newReplacement(key, pattern, value, regex))
+				}
+			}
+		}
+	}
+
+	failed, err := replaceInFiles(f.Reader, f.Writer, replacements)
+
+	if err != nil {
+		return err
+	}
+
+	return unappliedError(append(unapplied, failed...))
+}
+
+// getFilePattern returns the file name, the pattern, and whether the pattern is a regular expression from the
+// bracketed segments after the directive name.
+func (f ReplaceEnvScanner) getFilePattern(key string) (string, string, bool, error) {
+	segments, remaining, err := stringutil.BracketedSegments(key, 2)
+
+	if err != nil {
+		return "", "", false, err
+	}
+
+	regex, err := parseReplaceMode(remaining)
+
+	if err != nil {
+		return "", "", false, err
+	}
+
+	return stringutil.Unescape(segments[0]), segments[1], regex, nil
+}
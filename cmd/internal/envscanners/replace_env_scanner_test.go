@@ -0,0 +1,136 @@
+package envscanners
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"testing"
+)
+
+const htmlExample = "<script>window.config = {apiUrl: \"__API_URL__\", version: \"1.0.0\"};</script>\n" +
+	"<script src=\"__API_URL__/client.js\"></script>\n"
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		key      string
+		value    string
+		expected string
+	}{
+		{
+			"UDL_REPLACE[/app/index.html][__API_URL__]",
+			"https://api.example.org",
+			"<script>window.config = {apiUrl: \"https://api.example.org\", version: \"1.0.0\"};</script>\n" +
+				"<script src=\"https://api.example.org/client.js\"></script>\n",
+		},
+		{
+			"UDL_REPLACE[/app/index.html][__API_URL__][literal]",
+			"",
+			"<script>window.config = {apiUrl: \"\", version: \"1.0.0\"};</script>\n" +
+				"<script src=\"/client.js\"></script>\n",
+		},
+		{
+			"UDL_REPLACE[/app/index.html][version: \"[0-9.]+\"][regex]",
+			"version: \"2.0.0\"",
+			"<script>window.config = {apiUrl: \"__API_URL__\", version: \"2.0.0\"};</script>\n" +
+				"<script src=\"__API_URL__/client.js\"></script>\n",
+		},
+		{
+			"APPSETTING_UDL_REPLACE[/app/index.html][(apiUrl): \"__(\\w+)__\"][REGEX]",
+			"$1: \"${2}_FROM_ENV\"",
+			"<script>window.config = {apiUrl: \"API_URL_FROM_ENV\", version: \"1.0.0\"};</script>\n" +
+				"<script src=\"__API_URL__/client.js\"></script>\n",
+		},
+	}
+
+	for _, test := range tests {
+		writer := writers.StringWriter{}
+		scanner := ReplaceEnvScanner{
+			Env: envproviders.StringProvider{
+				Vars: map[string]string{
+					test.key: test.value,
+				},
+			},
+			Reader: readers.StringReader{
+				Files: &map[string]string{
+					"/app/index.html": htmlExample,
+				},
+			},
+			Writer: &writer,
+		}
+
+		err := scanner.ProcessEnvVars()
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		output := (*writer.Output)["/app/index.html"]
+
+		if output != test.expected {
+			t.Fatal(test.key + " must replace the matching text (was: \"" + output + "\")")
+		}
+	}
+}
+
+func TestReplaceMultiple(t *testing.T) {
+	writer := writers.StringWriter{}
+	scanner := ReplaceEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_REPLACE[/etc/nginx/nginx.conf][listen 80;]":                  "listen 8080;",
+				"UDL_REPLACE[/etc/nginx/nginx.conf][server_name [a-z.]+;][regex]": "server_name example.org;",
+			},
+		},
+		Reader: readers.StringReader{
+			Files: &map[string]string{
+				"/etc/nginx/nginx.conf": "server {\n  listen 80;\n  server_name localhost;\n}\n",
+			},
+		},
+		Writer: &writer,
+	}
+
+	err := scanner.ProcessEnvVars()
+
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	output := (*writer.Output)["/etc/nginx/nginx.conf"]
+
+	if output != "server {\n  listen 8080;\n  server_name example.org;\n}\n" {
+		t.Fatal("Every replacement must be applied to the file (was: \"" + output + "\")")
+	}
+}
+
+func TestReplaceNotApplied(t *testing.T) {
+	writer := writers.StringWriter{}
+	scanner := ReplaceEnvScanner{
+		Env: envproviders.StringProvider{
+			Vars: map[string]string{
+				"UDL_REPLACE[/app/missing.html][__API_URL__]":       "https://api.example.org",
+				"UDL_REPLACE[/app/index.html][(__API_URL__][regex]": "https://api.example.org",
+				"UDL_REPLACE[/app/index.html][__API_URL__][glob]":   "https://api.example.org",
+				"UDL_REPLACE[/app/index.html][__MISSING__]":         "https://api.example.org",
+			},
+		},
+		Reader: readers.StringReader{
+			Files: &map[string]string{
+				"/app/index.html": htmlExample,
+			},
+		},
+		Writer: &writer,
+	}
+
+	err := scanner.ProcessEnvVars()
+
+	var unappliedError *customerror.UnappliedError
+	if !errors.As(err, &unappliedError) || len(unappliedError.Errors) != 3 {
+		t.Fatal("Missing files, invalid patterns, and unknown modes must be reported")
+	}
+
+	if writer.Output != nil {
+		t.Fatal("Files must not be written when no text was replaced")
+	}
+}
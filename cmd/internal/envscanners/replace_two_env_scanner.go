@@ -0,0 +1,66 @@
+package envscanners
+
+import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+)
+
+// ReplaceEnvScannerTwo replaces text in files of any format, using plain env var names like UDL_REPLACE_URL, with the
+// file name, pattern, and replacement text defined in the value.
+type ReplaceEnvScannerTwo struct {
+	Env    envproviders.EnvironmentProvider
+	Reader readers.Reader
+	Writer writers.Writer
+}
+
+func (f ReplaceEnvScannerTwo) ProcessEnvVars() error {
+	replacements := map[string][]replacement{}
+	unapplied := []*customerror.UdlError{}
+
+	for _, e := range f.Env.GetAllEnvVars() {
+
+		if i := strings.Index(e, "="); i >= 0 {
+			key := e[:i]
+			value := e[i+1:]
+
+			for _, p := range prefixes.EnvVarPrefixes {
+				if strings.HasPrefix(key, p+"UDL_REPLACE_") {
+					file, pattern, newValue, err := f.getFilePattern(value)
+
+					if err != nil {
+						unapplied = append(unapplied, &customerror.UdlError{EnvVar: key, Err: err})
+						continue
+					}
+
+					newValue, regex := trimReplaceMode(newValue)
+					replacements[file] = append(replacements[file], newReplacement(key, pattern, newValue, regex))
+				}
+			}
+		}
+	}
+
+	failed, err := replaceInFiles(f.Reader, f.Writer, replacements)
+
+	if err != nil {
+		return err
+	}
+
+	return unappliedError(append(unapplied, failed...))
+}
+
+// getFilePattern returns the file name, the pattern, and the remaining text from the bracketed segments at the
+// start of the value.
+func (f ReplaceEnvScannerTwo) getFilePattern(value string) (string, string, string, error) {
+	segments, remaining, err := stringutil.BracketedSegments(value, 2)
+
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return stringutil.Unescape(segments[0]), segments[1], remaining, nil
+}
@@ -0,0 +1,61 @@
+package envscanners
+
+import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envproviders"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"testing"
+)
+
+func TestReplaceTwo(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{
+			"[/app/main.js][__API_URL__]https://api.example.org",
+			"const api = \"https://api.example.org\";\nconst timeout = 30;\n",
+		},
+		{
+			"[/app/main.js][__API_URL__][literal][https://api.example.org]",
+			"const api = \"[https://api.example.org]\";\nconst timeout = 30;\n",
+		},
+		{
+			"[/app/main.js][timeout = (\\d+)][regex]timeout = ${1}0",
+			"const api = \"__API_URL__\";\nconst timeout = 300;\n",
+		},
+		{
+			"[/app/main.js][\"__[A-Z_]+__\"][regex]null",
+			"const api = null;\nconst timeout = 30;\n",
+		},
+	}
+
+	for _, test := range tests {
+		writer := writers.StringWriter{}
+		scanner := ReplaceEnvScannerTwo{
+			Env: envproviders.StringProvider{
+				Vars: map[string]string{
+					"UDL_REPLACE_API": test.value,
+				},
+			},
+			Reader: readers.StringReader{
+				Files: &map[string]string{
+					"/app/main.js": "const api = \"__API_URL__\";\nconst timeout = 30;\n",
+				},
+			},
+			Writer: &writer,
+		}
+
+		err := scanner.ProcessEnvVars()
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		output := (*writer.Output)["/app/main.js"]
+
+		if output != test.expected {
+			t.Fatal(test.value + " must replace the matching text (was: \"" + output + "\")")
+		}
+	}
+}
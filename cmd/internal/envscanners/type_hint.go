@@ -0,0 +1,42 @@
+package envscanners
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"strings"
+)
+
+// parseTypeHint parses the optional type hint that follows the path of a directive like
+// UDL_SETVALUE[file][path][int].
+func parseTypeHint(remaining string) (manipulators.ValueType, error) {
+	if remaining == "" {
+		return manipulators.ValueTypeAuto, nil
+	}
+
+	segments, rest, err := stringutil.BracketedSegments(remaining, 1)
+	if err == nil && rest == "" {
+		if valueType, ok := manipulators.ParseValueType(segments[0]); ok {
+			return valueType, nil
+		}
+
+		return manipulators.ValueTypeAuto, errors.New("unknown type hint \"" + segments[0] + "\", which must be one of " +
+			strings.Join(manipulators.ValueTypeNames(), ", "))
+	}
+
+	return manipulators.ValueTypeAuto, errors.New("unexpected text \"" + remaining + "\" after the path")
+}
+
+// trimTypeHint removes the type hint from the start of the value of a directive like
+// UDL_SETVALUE_PORT=[file][path][int]8080. Values that do not start with a known type are returned unchanged.
+func trimTypeHint(value string) (string, manipulators.ValueType) {
+	if strings.HasPrefix(value, "[") {
+		if index := strings.Index(value, "]"); index != -1 {
+			if valueType, ok := manipulators.ParseValueType(value[1:index]); ok {
+				return value[index+1:], valueType
+			}
+		}
+	}
+
+	return value, manipulators.ValueTypeAuto
+}
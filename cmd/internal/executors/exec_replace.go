@@ -0,0 +1,33 @@
+package executors
+
+import (
+	"github.com/rs/zerolog/log"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// ExecReplace replaces UDL with the wrapped process, so the wrapped process keeps the pid of UDL, inherits its stdin,
+// stdout, stderr, and environment, and receives signals directly. Execute only returns if the process could not be
+// started.
+type ExecReplace struct {
+}
+
+func (e ExecReplace) Execute(executable string, args []string) error {
+	path, err := exec.LookPath(executable)
+	if err != nil {
+		log.Error().Msg("Failed to find " + executable + ": " + err.Error())
+		return err
+	}
+
+	log.Debug().Msg("Replacing UDL with " + path)
+
+	err = syscall.Exec(path, append([]string{executable}, args...), os.Environ())
+
+	log.Error().Msg("Failed to execute " + path + ": " + err.Error())
+	return &os.PathError{Op: "exec", Path: path, Err: err}
+}
+
+func (e ExecReplace) ExitCode(err error) int {
+	return exitCode(err)
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package executors
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestExecReplace(t *testing.T) {
+	t.Setenv("UDL_HELPER_FILE", filepath.Join(t.TempDir(), "pid"))
+	t.Setenv("UDL_HELPER_EXIT_CODE", "0")
+
+	// The helper checks that the process it was replaced with has the same pid, and was passed the environment
+	if actual := waitForExitCode(t, runHelperProcess(t, ExecuteAndWait{}, "exec")); actual != 0 {
+		t.Fatal("The process must be replaced by the application (exit code was " + strconv.Itoa(actual) + ")")
+	}
+}
+
+func TestExecReplaceNotFound(t *testing.T) {
+	executor := ExecReplace{}
+	err := executor.Execute("udl-executable-that-does-not-exist", []string{})
+
+	if actual := executor.ExitCode(err); actual != 127 {
+		t.Fatal("The exit code of a missing executable must be 127 (was " + strconv.Itoa(actual) + ")")
+	}
+}
@@ -2,44 +2,105 @@ package executors
 
 import (
 	"context"
+	"errors"
+	"github.com/rs/zerolog/log"
+	"io/fs"
 	"os"
 	"os/exec"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 )
 
+// ExecuteAndWait runs the wrapped process as a child of UDL, forwarding signals to it and waiting for it to exit.
 type ExecuteAndWait struct {
+	// ProcessGroup runs the wrapped process in its own process group, and forwards signals to the whole group
+	ProcessGroup bool
+	// Subreaper registers UDL as a child subreaper, so it reaps orphaned processes without being PID 1
+	Subreaper bool
+	// StopSignal replaces the SIGTERM or SIGINT signals sent to stop UDL, or is nil to forward the signals as is
+	StopSignal os.Signal
+	// StopTimeout is how long to wait after a stop signal before killing the wrapped process, or 0 to wait forever
+	StopTimeout time.Duration
 }
 
 func (e ExecuteAndWait) Execute(executable string, args []string) error {
+	if e.Subreaper {
+		if err := setSubreaper(); err != nil {
+			log.Error().Msg("Failed to register as a child subreaper: " + err.Error())
+		}
+	}
+
+	// The wrapped process shares the stdin, stdout, and stderr of UDL, including any terminal, so interactive
+	// applications read from and write to the terminal directly
 	cmd := exec.Command(executable, args...)
+	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	// A wrapped process run in the foreground of a terminal gets its own process group, which is moved to the
+	// foreground, so the signals sent by the terminal are only received by the wrapped process, and every signal
+	// received by UDL can be forwarded
+	if e.ProcessGroup || isForegroundProcessGroup() {
+		restoreTerminal := setProcessGroup(cmd)
+		defer restoreTerminal()
+	}
+
 	if err := cmd.Start(); err != nil {
+		log.Error().Msg("Failed to start " + executable + ": " + err.Error())
 		return err
 	}
 
 	ctx := context.Background()
 
-	e.wait(ctx, cmd, stopSignal(), 0)
+	interrupt := e.StopSignal
+	if interrupt == nil {
+		interrupt = stopSignal()
+	}
 
-	return nil
+	return e.wait(ctx, cmd, interrupt, e.StopTimeout)
 }
 
 func (e ExecuteAndWait) ExitCode(err error) int {
-	if err != nil {
-		if exiterr, ok := err.(*exec.ExitError); ok {
-			return exiterr.ExitCode()
-		} else {
-			// there was an error, but we couldn't get the exit code
-			// assume we want to return a non-zero exit code in this case
-			return 1
+	return exitCode(err)
+}
+
+// exitCode returns the exit code of the wrapped process from the error returned by Execute. Processes killed by a
+// signal return 128 plus the signal number, and executables that could not be run return 126 or 127, matching the
+// exit codes reported by shells.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var statusErr *exitStatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.status.Signaled() {
+			return 128 + int(statusErr.status.Signal())
+		}
+		return statusErr.status.ExitStatus()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
 		}
+		return exitErr.ExitCode()
+	}
+
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return 127
+	}
+
+	if errors.Is(err, fs.ErrPermission) {
+		return 126
 	}
 
-	return 0
+	// there was an error, but we couldn't get the exit code
+	// assume we want to return a non-zero exit code in this case
+	return 1
 }
 
 // stopSignal returns the appropriate signal to use to request that a process
@@ -54,6 +115,9 @@ func stopSignal() os.Signal {
 	return os.Interrupt
 }
 
+// wait forwards signals to the wrapped process until it exits. Signals that stop the process, or the context being
+// done, start the kill delay, after which the process is killed if it has not exited. Signals that stop the process
+// are replaced with the StopSignal, if it is set.
 func (e ExecuteAndWait) wait(ctx context.Context, cmd *exec.Cmd, interrupt os.Signal, killDelay time.Duration) error {
 	if cmd.Process == nil {
 		panic("waitOrStop called with a nil cmd.Process — missing Start call?")
@@ -62,53 +126,79 @@ func (e ExecuteAndWait) wait(ctx context.Context, cmd *exec.Cmd, interrupt os.Si
 		panic("waitOrStop requires a non-nil interrupt signal")
 	}
 
-	cancelChan := make(chan os.Signal, 1)
-	// catch SIGETRM or SIGINTERRUPT
-	signal.Notify(cancelChan, syscall.SIGTERM, syscall.SIGINT)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
 
-	errc := make(chan error)
+	waitc := make(chan error, 1)
 	go func() {
+		waitc <- waitForProcess(cmd)
+	}()
+
+	var killTimer <-chan time.Time
+	var interruptErr error
+	done := ctx.Done()
+
+	for {
 		select {
-		case errc <- nil:
-			return
-		case interrupt = <-cancelChan:
-		case <-ctx.Done():
-		}
+		case waitErr := <-waitc:
+			// Report ctx.Err() as the reason the process was interrupted
+			if interruptErr != nil {
+				return interruptErr
+			}
+			return waitErr
+		case received := <-signals:
+			forwarded := received
+			if isStopSignal(received) && e.StopSignal != nil {
+				forwarded = e.StopSignal
+			}
 
-		err := cmd.Process.Signal(interrupt)
-		if err == nil {
-			err = ctx.Err() // Report ctx.Err() as the reason we interrupted.
-		} else if err.Error() == "os: process already finished" {
-			errc <- nil
-			return
-		}
+			log.Debug().Msg("Forwarding signal " + forwarded.String() + " to process " + strconv.Itoa(cmd.Process.Pid))
+			e.signal(cmd, forwarded)
 
-		if killDelay > 0 {
-			timer := time.NewTimer(killDelay)
-			select {
-			// Report ctx.Err() as the reason we interrupted the process...
-			case errc <- ctx.Err():
-				timer.Stop()
-				return
-			// ...but after killDelay has elapsed, fall back to a stronger signal.
-			case <-timer.C:
+			if isStopSignal(received) && killDelay > 0 && killTimer == nil {
+				killTimer = time.After(killDelay)
 			}
+		case <-done:
+			done = nil
+			interruptErr = ctx.Err()
+			e.signal(cmd, interrupt)
 
-			// Wait still hasn't returned.
-			// Kill the process harder to make sure that it exits.
-			//
-			// Ignore any error: if cmd.Process has already terminated, we still
-			// want to send ctx.Err() (or the error from the Interrupt call)
-			// to properly attribute the signal that may have terminated it.
-			_ = cmd.Process.Kill()
+			if killDelay > 0 && killTimer == nil {
+				killTimer = time.After(killDelay)
+			}
+		case <-killTimer:
+			// The process has not exited after the kill delay, so kill it harder to make sure that it exits
+			log.Warn().Msg("Killing process " + strconv.Itoa(cmd.Process.Pid) + ", which did not stop within " + killDelay.String())
+			e.signal(cmd, os.Kill)
 		}
+	}
+}
 
-		errc <- err
-	}()
+// signal sends a signal to the wrapped process, or its process group. Errors are ignored, as the process may have
+// already exited.
+func (e ExecuteAndWait) signal(cmd *exec.Cmd, sig os.Signal) {
+	var err error
+	if e.ProcessGroup {
+		err = signalProcessGroup(cmd, sig)
+	} else {
+		err = cmd.Process.Signal(sig)
+	}
 
-	waitErr := cmd.Wait()
-	if interruptErr := <-errc; interruptErr != nil {
-		return interruptErr
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		log.Debug().Msg("Failed to send signal " + sig.String() + ": " + err.Error())
 	}
-	return waitErr
+}
+
+// exitStatusError is returned when the wrapped process is reaped by UDL rather than by exec.Cmd.Wait.
+type exitStatusError struct {
+	status syscall.WaitStatus
+}
+
+func (e *exitStatusError) Error() string {
+	if e.status.Signaled() {
+		return "signal: " + e.status.Signal().String()
+	}
+
+	return "exit status " + strconv.Itoa(e.status.ExitStatus())
 }
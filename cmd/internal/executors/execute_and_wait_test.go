@@ -0,0 +1,178 @@
+package executors
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is not a real test. It is run by the other tests as the wrapped process, and performs the
+// action in UDL_HELPER_ACTION:
+//   - "exit" exits with the code in UDL_HELPER_EXIT_CODE
+//   - "kill" kills itself
+//   - "signal" waits for a forwarded signal, and exits with 100 plus the signal number
+//   - "ignore" ignores forwarded signals, and never exits
+//   - "orphan" starts a child process that exits immediately, and exits without waiting for it
+//   - "stdin" exits with 0 if stdin contains the text in UDL_HELPER_STDIN
+//   - "exec" replaces itself with a helper that performs the "pid" action
+//   - "pid" exits with 0 if its pid matches the pid in UDL_HELPER_FILE
+//   - "wrap" runs a helper that performs the "signal" action with ExecuteAndWait, and exits with its exit code
+//
+// The file in UDL_HELPER_FILE is written once the helper is ready to receive signals, or with the pid of the
+// orphaned or replaced process.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("UDL_HELPER_PROCESS") != "true" {
+		return
+	}
+
+	switch os.Getenv("UDL_HELPER_ACTION") {
+	case "kill":
+		process, _ := os.FindProcess(os.Getpid())
+		_ = process.Kill()
+	case "signal":
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, forwardedSignals...)
+		_ = os.WriteFile(os.Getenv("UDL_HELPER_FILE"), []byte("ready"), 0644)
+
+		exitCode := 100
+		if received, ok := (<-signals).(syscall.Signal); ok {
+			exitCode += int(received)
+		}
+		os.Exit(exitCode)
+	case "ignore":
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, forwardedSignals...)
+		_ = os.WriteFile(os.Getenv("UDL_HELPER_FILE"), []byte("ready"), 0644)
+
+		for {
+			<-signals
+		}
+	case "stdin":
+		contents, _ := io.ReadAll(os.Stdin)
+		if string(contents) != os.Getenv("UDL_HELPER_STDIN") {
+			os.Exit(1)
+		}
+	case "exec":
+		_ = os.WriteFile(os.Getenv("UDL_HELPER_FILE"), []byte(strconv.Itoa(os.Getpid())), 0644)
+		_ = os.Setenv("UDL_HELPER_ACTION", "pid")
+		_ = ExecReplace{}.Execute(os.Args[0], []string{"-test.run=^TestHelperProcess$"})
+		os.Exit(1)
+	case "pid":
+		contents, _ := os.ReadFile(os.Getenv("UDL_HELPER_FILE"))
+		if string(contents) != strconv.Itoa(os.Getpid()) {
+			os.Exit(1)
+		}
+	case "wrap":
+		_ = os.Setenv("UDL_HELPER_ACTION", "signal")
+		executor := ExecuteAndWait{}
+		os.Exit(executor.ExitCode(executor.Execute(os.Args[0], []string{"-test.run=^TestHelperProcess$"})))
+	case "orphan":
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmd.Env = append(os.Environ(), "UDL_HELPER_ACTION=exit", "UDL_HELPER_EXIT_CODE=0")
+		if err := cmd.Start(); err != nil {
+			os.Exit(1)
+		}
+		_ = os.WriteFile(os.Getenv("UDL_HELPER_FILE"), []byte(strconv.Itoa(cmd.Process.Pid)), 0644)
+
+		// Give the orphan time to exit, so it is a zombie when this process exits
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	exitCode, _ := strconv.Atoi(os.Getenv("UDL_HELPER_EXIT_CODE"))
+	os.Exit(exitCode)
+}
+
+func runHelperProcess(t *testing.T, executor ExecuteAndWait, action string) chan int {
+	t.Setenv("UDL_HELPER_PROCESS", "true")
+	t.Setenv("UDL_HELPER_ACTION", action)
+
+	exitCodes := make(chan int, 1)
+	go func() {
+		err := executor.Execute(os.Args[0], []string{"-test.run=^TestHelperProcess$"})
+		exitCodes <- executor.ExitCode(err)
+	}()
+
+	return exitCodes
+}
+
+func waitForExitCode(t *testing.T, exitCodes chan int) int {
+	select {
+	case exitCode := <-exitCodes:
+		return exitCode
+	case <-time.After(30 * time.Second):
+		t.Fatal("The wrapped process did not exit")
+	}
+
+	return -1
+}
+
+func TestExitCode(t *testing.T) {
+	for _, exitCode := range []int{0, 1, 3, 42} {
+		t.Setenv("UDL_HELPER_EXIT_CODE", strconv.Itoa(exitCode))
+
+		if actual := waitForExitCode(t, runHelperProcess(t, ExecuteAndWait{}, "exit")); actual != exitCode {
+			t.Fatal("The exit code must be " + strconv.Itoa(exitCode) + " (was " + strconv.Itoa(actual) + ")")
+		}
+	}
+}
+
+func TestStdin(t *testing.T) {
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer stdin.Close()
+
+	if _, err := stdin.WriteString("hello\nworld\n"); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	originalStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = originalStdin }()
+
+	t.Setenv("UDL_HELPER_STDIN", "hello\nworld\n")
+	t.Setenv("UDL_HELPER_EXIT_CODE", "0")
+
+	for _, executor := range []ExecuteAndWait{{}, {ProcessGroup: true}} {
+		if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if actual := waitForExitCode(t, runHelperProcess(t, executor, "stdin")); actual != 0 {
+			t.Fatal("The wrapped process must read the stdin of UDL (exit code was " + strconv.Itoa(actual) + ")")
+		}
+	}
+}
+
+func TestExitCodeKilled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Processes are not killed with signals on Windows")
+	}
+
+	// SIGKILL is signal 9
+	if actual := waitForExitCode(t, runHelperProcess(t, ExecuteAndWait{}, "kill")); actual != 137 {
+		t.Fatal("The exit code of a killed process must be 137 (was " + strconv.Itoa(actual) + ")")
+	}
+}
+
+func TestExitCodeNotFound(t *testing.T) {
+	executor := ExecuteAndWait{}
+
+	for _, executable := range []string{"udl-executable-that-does-not-exist", "/udl/executable/that/does/not/exist"} {
+		err := executor.Execute(executable, []string{})
+
+		if actual := executor.ExitCode(err); actual != 127 {
+			t.Fatal("The exit code of a missing executable must be 127 (was " + strconv.Itoa(actual) + ")")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package executors
+
+import (
+	"golang.org/x/sys/unix"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// openTerminal opens a new pseudo terminal, and returns the master and slave ends.
+func openTerminal(t *testing.T) (*os.File, *os.File) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skip("Pseudo terminals are not available: " + err.Error())
+	}
+	t.Cleanup(func() { _ = master.Close() })
+
+	if err := unix.IoctlSetPointerInt(int(master.Fd()), unix.TIOCSPTLCK, 0); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	number, err := unix.IoctlGetInt(int(master.Fd()), unix.TIOCGPTN)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	slave, err := os.OpenFile("/dev/pts/"+strconv.Itoa(number), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	t.Cleanup(func() { _ = slave.Close() })
+
+	return master, slave
+}
+
+func TestForwardSignalsWithTerminal(t *testing.T) {
+	_, slave := openTerminal(t)
+
+	for _, sig := range []syscall.Signal{syscall.SIGINT, syscall.SIGQUIT, syscall.SIGWINCH} {
+		file := filepath.Join(t.TempDir(), "ready")
+
+		// UDL is run as the leader of a new session, with the terminal as its controlling terminal, so it is in the
+		// foreground of the terminal like a container run with "docker run -it"
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmd.Env = append(os.Environ(), "UDL_HELPER_PROCESS=true", "UDL_HELPER_ACTION=wrap", "UDL_HELPER_FILE="+file)
+		cmd.Stdin = slave
+		cmd.Stdout = slave
+		cmd.Stderr = slave
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		exitCodes := make(chan int, 1)
+		go func() {
+			exitCodes <- exitCode(cmd.Wait())
+		}()
+
+		waitForFile(t, file)
+
+		// Signals sent to UDL by something other than the terminal, like "docker kill", must still be forwarded
+		if err := cmd.Process.Signal(sig); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		expected := 100 + int(sig)
+		if actual := waitForExitCode(t, exitCodes); actual != expected {
+			t.Fatal("The signal " + sig.String() + " must be forwarded (exit code was " + strconv.Itoa(actual) + ")")
+		}
+	}
+}
@@ -0,0 +1,135 @@
+//go:build !windows
+
+package executors
+
+import (
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/unix"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+// forwardedSignals are the signals caught by UDL and sent to the wrapped process. Signals used for job control, and
+// signals like SIGSEGV that indicate an error in UDL itself, are not forwarded.
+var forwardedSignals = []os.Signal{
+	syscall.SIGHUP,
+	syscall.SIGINT,
+	syscall.SIGQUIT,
+	syscall.SIGTERM,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+	syscall.SIGALRM,
+	syscall.SIGWINCH,
+	syscall.SIGCONT,
+}
+
+// signalNames maps the names accepted by ParseSignal to signals.
+var signalNames = map[string]syscall.Signal{
+	"HUP":   syscall.SIGHUP,
+	"INT":   syscall.SIGINT,
+	"QUIT":  syscall.SIGQUIT,
+	"KILL":  syscall.SIGKILL,
+	"TERM":  syscall.SIGTERM,
+	"USR1":  syscall.SIGUSR1,
+	"USR2":  syscall.SIGUSR2,
+	"ALRM":  syscall.SIGALRM,
+	"WINCH": syscall.SIGWINCH,
+	"CONT":  syscall.SIGCONT,
+}
+
+// isForegroundProcessGroup returns true if UDL is in the foreground process group of the terminal on stdin.
+func isForegroundProcessGroup() bool {
+	foreground, err := unix.IoctlGetInt(int(os.Stdin.Fd()), unix.TIOCGPGRP)
+	return err == nil && foreground == syscall.Getpgrp()
+}
+
+// isStopSignal returns true for the signals sent to stop a container.
+func isStopSignal(sig os.Signal) bool {
+	return sig == syscall.SIGTERM || sig == syscall.SIGINT
+}
+
+// setProcessGroup runs the wrapped process in its own process group. If UDL is in the foreground of the terminal on
+// stdin, the new process group is moved to the foreground, so the wrapped process can read from the terminal and
+// receives the signals sent by the terminal, like SIGINT for Ctrl-C and SIGWINCH when the terminal is resized. The
+// returned function moves UDL back to the foreground once the wrapped process has exited.
+func setProcessGroup(cmd *exec.Cmd) func() {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if !isForegroundProcessGroup() {
+		return func() {}
+	}
+
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = int(os.Stdin.Fd())
+
+	return func() {
+		// Processes in the background are sent SIGTTOU when they change the foreground process group
+		signal.Ignore(syscall.SIGTTOU)
+		defer signal.Reset(syscall.SIGTTOU)
+
+		if err := unix.IoctlSetPointerInt(int(os.Stdin.Fd()), unix.TIOCSPGRP, syscall.Getpgrp()); err != nil {
+			log.Debug().Msg("Failed to move UDL to the foreground of the terminal: " + err.Error())
+		}
+	}
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	unixSignal, ok := sig.(syscall.Signal)
+	if !ok {
+		return cmd.Process.Signal(sig)
+	}
+
+	// A negative pid sends the signal to every process in the group
+	return syscall.Kill(-cmd.Process.Pid, unixSignal)
+}
+
+// waitForProcess waits for the wrapped process to exit. Orphaned processes are re-parented to PID 1, or to a child
+// subreaper, so in either case UDL reaps every child that exits to prevent zombies, and returns the exit status of the
+// wrapped process once it is reaped.
+func waitForProcess(cmd *exec.Cmd) error {
+	if os.Getpid() != 1 && !isSubreaper() {
+		return cmd.Wait()
+	}
+
+	children := make(chan os.Signal, 1)
+	signal.Notify(children, syscall.SIGCHLD)
+	defer signal.Stop(children)
+
+	for {
+		var exitErr error
+		exited := false
+
+		// Reap every child that has exited, including any orphans re-parented as the wrapped process exited
+		for {
+			var status syscall.WaitStatus
+			pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+
+			if err == syscall.EINTR {
+				continue
+			}
+
+			if err != nil || pid <= 0 {
+				break
+			}
+
+			if pid != cmd.Process.Pid {
+				log.Debug().Msg("Reaped orphaned process " + strconv.Itoa(pid))
+				continue
+			}
+
+			exited = true
+			if !status.Exited() || status.ExitStatus() != 0 {
+				exitErr = &exitStatusError{status: status}
+			}
+		}
+
+		if exited {
+			return exitErr
+		}
+
+		<-children
+	}
+}
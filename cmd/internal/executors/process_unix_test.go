@@ -0,0 +1,126 @@
+//go:build !windows
+
+package executors
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitForFile waits for the helper process to write a file, and returns its contents.
+func waitForFile(t *testing.T, file string) string {
+	for start := time.Now(); time.Since(start) < 30*time.Second; time.Sleep(10 * time.Millisecond) {
+		if contents, err := os.ReadFile(file); err == nil && len(contents) != 0 {
+			return string(contents)
+		}
+	}
+
+	t.Fatal("The helper process did not write " + file)
+	return ""
+}
+
+func TestForwardSignals(t *testing.T) {
+	tests := []struct {
+		executor ExecuteAndWait
+		signal   syscall.Signal
+	}{
+		{ExecuteAndWait{}, syscall.SIGUSR1},
+		{ExecuteAndWait{}, syscall.SIGHUP},
+		{ExecuteAndWait{}, syscall.SIGTERM},
+		{ExecuteAndWait{ProcessGroup: true}, syscall.SIGUSR2},
+		{ExecuteAndWait{ProcessGroup: true}, syscall.SIGWINCH},
+	}
+
+	for _, test := range tests {
+		file := filepath.Join(t.TempDir(), "ready")
+		t.Setenv("UDL_HELPER_FILE", file)
+
+		exitCodes := runHelperProcess(t, test.executor, "signal")
+		waitForFile(t, file)
+
+		if err := syscall.Kill(os.Getpid(), test.signal); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		expected := 100 + int(test.signal)
+		if actual := waitForExitCode(t, exitCodes); actual != expected {
+			t.Fatal("The signal " + test.signal.String() + " must be forwarded (exit code was " + strconv.Itoa(actual) + ")")
+		}
+	}
+}
+
+func TestStopSignal(t *testing.T) {
+	for _, received := range []syscall.Signal{syscall.SIGTERM, syscall.SIGINT} {
+		file := filepath.Join(t.TempDir(), "ready")
+		t.Setenv("UDL_HELPER_FILE", file)
+
+		exitCodes := runHelperProcess(t, ExecuteAndWait{StopSignal: syscall.SIGQUIT}, "signal")
+		waitForFile(t, file)
+
+		if err := syscall.Kill(os.Getpid(), received); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		expected := 100 + int(syscall.SIGQUIT)
+		if actual := waitForExitCode(t, exitCodes); actual != expected {
+			t.Fatal("The signal " + received.String() + " must be replaced with SIGQUIT (exit code was " + strconv.Itoa(actual) + ")")
+		}
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ready")
+	t.Setenv("UDL_HELPER_FILE", file)
+
+	exitCodes := runHelperProcess(t, ExecuteAndWait{StopTimeout: 200 * time.Millisecond}, "ignore")
+	waitForFile(t, file)
+
+	// Signals that do not stop the process do not start the timeout
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	select {
+	case <-exitCodes:
+		t.Fatal("The process must not be killed by signals other than SIGTERM or SIGINT")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if actual := waitForExitCode(t, exitCodes); actual != 137 {
+		t.Fatal("The process must be killed after the stop timeout (exit code was " + strconv.Itoa(actual) + ")")
+	}
+}
+
+func TestReapOrphans(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Child subreapers are only supported on Linux")
+	}
+
+	file := filepath.Join(t.TempDir(), "pid")
+	t.Setenv("UDL_HELPER_FILE", file)
+	t.Setenv("UDL_HELPER_EXIT_CODE", "0")
+
+	if actual := waitForExitCode(t, runHelperProcess(t, ExecuteAndWait{Subreaper: true}, "orphan")); actual != 0 {
+		t.Fatal("The exit code must be 0 (was " + strconv.Itoa(actual) + ")")
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(waitForFile(t, file)))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// A zombie process can still be sent a signal, while a reaped process can not
+	if err := syscall.Kill(pid, 0); err != syscall.ESRCH {
+		t.Fatal("The orphaned process " + strconv.Itoa(pid) + " must be reaped")
+	}
+}
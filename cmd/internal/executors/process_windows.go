@@ -0,0 +1,44 @@
+package executors
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// forwardedSignals are the signals caught by UDL and sent to the wrapped process.
+var forwardedSignals = []os.Signal{
+	syscall.SIGINT,
+	syscall.SIGTERM,
+}
+
+// signalNames maps the names accepted by ParseSignal to signals.
+var signalNames = map[string]syscall.Signal{
+	"INT":  syscall.SIGINT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+// isForegroundProcessGroup returns false, as process groups are not supported on Windows.
+func isForegroundProcessGroup() bool {
+	return false
+}
+
+// isStopSignal returns true for the signals sent to stop a container.
+func isStopSignal(sig os.Signal) bool {
+	return sig == syscall.SIGTERM || sig == syscall.SIGINT
+}
+
+// setProcessGroup does nothing, as process groups are not supported on Windows.
+func setProcessGroup(cmd *exec.Cmd) func() {
+	return func() {}
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return cmd.Process.Signal(sig)
+}
+
+// waitForProcess waits for the wrapped process to exit. Windows does not have zombie processes to reap.
+func waitForProcess(cmd *exec.Cmd) error {
+	return cmd.Wait()
+}
@@ -0,0 +1,24 @@
+package executors
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ParseSignal returns the signal with a name like "SIGQUIT" or "QUIT", or a number like "3".
+func ParseSignal(name string) (os.Signal, error) {
+	trimmedName := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+
+	if sig, ok := signalNames[trimmedName]; ok {
+		return sig, nil
+	}
+
+	if number, err := strconv.Atoi(trimmedName); err == nil && number > 0 {
+		return syscall.Signal(number), nil
+	}
+
+	return nil, errors.New("\"" + name + "\" is not a supported signal")
+}
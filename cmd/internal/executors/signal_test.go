@@ -0,0 +1,26 @@
+package executors
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	for _, name := range []string{"SIGTERM", "sigterm", "TERM", " term ", "15"} {
+		sig, err := ParseSignal(name)
+
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		if sig != syscall.SIGTERM {
+			t.Fatal("\"" + name + "\" must be parsed as SIGTERM (was " + sig.String() + ")")
+		}
+	}
+
+	for _, name := range []string{"", "SIGFOO", "0", "-1"} {
+		if _, err := ParseSignal(name); err == nil {
+			t.Fatal("\"" + name + "\" must not be parsed as a signal")
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package executors
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const (
+	prSetChildSubreaper = 36
+	prGetChildSubreaper = 37
+)
+
+// setSubreaper registers UDL as a child subreaper, so orphaned descendants are re-parented to UDL rather than PID 1.
+func setSubreaper() error {
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, 1, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func isSubreaper() bool {
+	var subreaper int32
+	_, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prGetChildSubreaper, uintptr(unsafe.Pointer(&subreaper)), 0)
+	return errno == 0 && subreaper != 0
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package executors
+
+import "errors"
+
+// setSubreaper returns an error, as child subreapers are only supported on Linux.
+func setSubreaper() error {
+	return errors.New("child subreapers are only supported on Linux")
+}
+
+func isSubreaper() bool {
+	return false
+}
@@ -0,0 +1,60 @@
+package manipulators
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// arrayIndexPattern matches the path elements that address an array: zero based indexes like "0", negative indexes
+// counting back from the end of the array like "-1", "-" to append an item, and "+N" to insert an item at index N.
+var arrayIndexPattern = regexp.MustCompile("^(-|\\+?-?[0-9]+)$")
+
+// IsArrayIndex returns true if the path element addresses an item in an array.
+func IsArrayIndex(element string) bool {
+	return arrayIndexPattern.MatchString(element)
+}
+
+// IsArrayInsert returns true if the path element adds a new item to an array.
+func IsArrayInsert(element string) bool {
+	return element == "-" || (len(element) > 1 && element[0] == '+')
+}
+
+// ResolveArrayIndex converts a path element into an index into an array with the supplied length. The returned
+// boolean is true if a new item is to be inserted at the returned index, shifting any existing items along.
+func ResolveArrayIndex(element string, length int) (int, bool, error) {
+	if element == "-" {
+		return length, true, nil
+	}
+
+	insert := IsArrayInsert(element)
+	number := element
+	if insert {
+		number = element[1:]
+	}
+
+	index, err := strconv.ParseInt(number, 10, 32)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if insert {
+		if index < 0 || index > int64(length) {
+			return 0, false, errors.New("insert indexes must be between 0 and the length of the array (array has " + fmt.Sprint(length) + " elements, index was " + fmt.Sprint(index) + ")")
+		}
+
+		return int(index), true, nil
+	}
+
+	// Negative indexes count back from the end of the array
+	if index < 0 {
+		index += int64(length)
+	}
+
+	if index < 0 || index >= int64(length) {
+		return 0, false, errors.New("integer indexes must be within the existing array's bounds (array has " + fmt.Sprint(length) + " elements, index was " + element + ")")
+	}
+
+	return int(index), false, nil
+}
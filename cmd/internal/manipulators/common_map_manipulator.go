@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"reflect"
 	"strconv"
 	"strings"
 )
@@ -14,107 +17,134 @@ type CommonMapManipulator struct {
 	Unmarshaller Unmarshaller
 }
 
-func (m CommonMapManipulator) ProcessMap(result map[string]any, valueSpec string, value string) (map[string]any, error) {
-	path := strings.Split(valueSpec, ":")
+func (m CommonMapManipulator) ProcessMap(result map[string]any, valueSpec string, value string, valueType ValueType) (map[string]any, error) {
+	path := SplitPath(valueSpec)
 
 	var current any = result
+	// setCurrent replaces the current object in its parent, which is required when items are added to an array
+	setCurrent := func(value any) {}
 	for i, p := range path {
+		last := i == len(path)-1
 
-		// If this part of the path is a number, it represents an array index
-		if index, err := strconv.ParseInt(p, 10, 16); err == nil {
-
-			if i != len(path)-1 {
-				return nil, errors.New("integer indexes must be the final element in the path (index was element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
-			}
+		// If this part of the path is a number, it represents an array index, unless the current object is a map
+		// with numeric keys
+		if IsArrayIndex(p) && m.getType(current) != "object" {
 
 			objectType := m.getType(current)
 			if objectType != "array" {
-				return nil, errors.New("integer indexes must be used against an existing array (object type was " + objectType + ")")
+				return nil, errors.New("integer indexes must be used against an existing array (object type was " + objectType + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
 			}
 
 			array := current.([]any)
 
-			if int64(len(array)) <= index {
-				return nil, errors.New("integer indexes must be within the existing array's bounds (array has " + fmt.Sprint(len(array)) + " elements, index was " + fmt.Sprint(index) + ")")
+			index, insert, err := ResolveArrayIndex(p, len(array))
+			if err != nil {
+				return nil, errors.New(err.Error() + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements")
 			}
 
-			switch m.getType(array[index]) {
-			case "number":
-				number, err := strconv.ParseFloat(value, 64)
-				if err != nil {
-					array[index] = value
-				} else {
-					array[index] = number
+			if insert {
+				// New items retain the type of their siblings
+				var sibling any
+				if len(array) != 0 {
+					sibling = array[m.min(index, len(array)-1)]
 				}
-			case "boolean":
-				bool, err := strconv.ParseBool(value)
-				if err != nil {
-					array[index] = value
-				} else {
-					array[index] = bool
+
+				array = append(array[:index], append([]any{sibling}, array[index:]...)...)
+				setCurrent(array)
+
+				if !last {
+					array[index] = m.newContainer(path[i+1])
 				}
-			case "object":
-				var objectValue map[string]any
-				err = json.Unmarshal([]byte(value), &objectValue)
+			}
+
+			if last {
+				converted, err := m.ConvertTypedValue(array[index], value, valueType)
 				if err != nil {
-					array[index] = value
-				} else {
-					array[index] = objectValue
+					return nil, err
 				}
-			case "array":
-				var objectValue []any
-				err = json.Unmarshal([]byte(value), &objectValue)
+				array[index] = converted
+			} else {
+				current = array[index]
+				setCurrent = func(value any) { array[index] = value }
+			}
+		} else {
+			currentMap, ok := current.(map[string]any)
+			if !ok {
+				return nil, errors.New("failed to navigate through JSON object to desired location (object type was " + m.getType(current) + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			key := PathKey(p)
+			if last {
+				converted, err := m.ConvertTypedValue(currentMap[key], value, valueType)
 				if err != nil {
-					array[index] = value
-				} else {
-					array[index] = objectValue
+					return nil, err
 				}
-			default:
-				array[index] = value
+				currentMap[key] = converted
+			} else {
+				// Missing (or null) intermediate keys are created as empty objects, which allows
+				// new sections to be added to sparse config files
+				if currentMap[key] == nil {
+					currentMap[key] = m.newContainer(path[i+1])
+				}
+				current = currentMap[key]
+				setCurrent = func(value any) { currentMap[key] = value }
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteFromMap removes the key or array item at the end of the path. Paths that do not exist are ignored, so
+// deleting a value is safe to repeat.
+func (m CommonMapManipulator) DeleteFromMap(result map[string]any, valueSpec string) (map[string]any, error) {
+	path := SplitPath(valueSpec)
+
+	var current any = result
+	setCurrent := func(value any) {}
+	for i, p := range path {
+		last := i == len(path)-1
+
+		if IsArrayIndex(p) && m.getType(current) != "object" {
+			if IsArrayInsert(p) {
+				return nil, errors.New("append and insert indexes can not be used when deleting values (path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			objectType := m.getType(current)
+			if objectType != "array" {
+				return nil, errors.New("integer indexes must be used against an existing array (object type was " + objectType + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			array := current.([]any)
+
+			index, _, err := ResolveArrayIndex(p, len(array))
+			if err != nil {
+				// There is no item to delete
+				return result, nil
+			}
+
+			if last {
+				setCurrent(append(array[:index:index], array[index+1:]...))
+			} else {
+				current = array[index]
+				setCurrent = func(value any) { array[index] = value }
 			}
 		} else {
 			currentMap, ok := current.(map[string]any)
-			if ok {
-				if i < len(path)-1 {
-					current = currentMap[p]
-				} else {
-					// Attempt to match the destination type, falling back to a string if the supplied value
-					// does not match the destination.
-					switch m.getType(currentMap[p]) {
-					case "number":
-						number, err := strconv.ParseFloat(value, 64)
-						if err != nil {
-							currentMap[p] = value
-						} else {
-							currentMap[p] = number
-						}
-					case "boolean":
-						bool, err := strconv.ParseBool(value)
-						if err != nil {
-							currentMap[p] = value
-						} else {
-							currentMap[p] = bool
-						}
-					case "object":
-						objectValue, err := m.Unmarshaller.UnmarshalMap(value)
-						if err != nil {
-							currentMap[p] = value
-						} else {
-							currentMap[p] = objectValue
-						}
-					case "array":
-						objectValue, err := m.Unmarshaller.UnmarshalArray(value)
-						if err != nil {
-							currentMap[p] = value
-						} else {
-							currentMap[p] = objectValue
-						}
-					default:
-						currentMap[p] = value
-					}
-				}
+			if !ok {
+				return nil, errors.New("failed to navigate through JSON object to desired location (object type was " + m.getType(current) + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			key := PathKey(p)
+			if last {
+				delete(currentMap, key)
 			} else {
-				return nil, errors.New("failed to navigate through JSON object to desired location")
+				current, ok = currentMap[key]
+				if !ok {
+					// There is no key to delete
+					return result, nil
+				}
+				setCurrent = func(value any) { currentMap[key] = value }
 			}
 		}
 	}
@@ -122,7 +152,247 @@ func (m CommonMapManipulator) ProcessMap(result map[string]any, valueSpec string
 	return result, nil
 }
 
+// newContainer creates an empty array if the next path element adds an item to an array, or an empty object otherwise.
+func (m CommonMapManipulator) newContainer(nextElement string) any {
+	if IsArrayInsert(nextElement) {
+		return []any{}
+	}
+
+	return map[string]any{}
+}
+
+func (m CommonMapManipulator) min(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// ConvertValue attempts to match the type of the existing value, falling back to a string if the supplied value
+// does not match the destination.
+func (m CommonMapManipulator) ConvertValue(existing any, value string) any {
+	switch m.getType(existing) {
+	case "number":
+		// Integers remain integers of the same type, while other numbers are stored as floats
+		if integer, ok := m.convertInteger(existing, value); ok {
+			return integer
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			if existingNumber, ok := existing.(json.Number); ok {
+				return m.formatFloat(existingNumber, number)
+			}
+			return number
+		}
+	case "boolean":
+		bool, err := strconv.ParseBool(value)
+		if err == nil {
+			return bool
+		}
+	case "object":
+		// Objects are parsed in the native format of the file, with JSON accepted as a fallback
+		objectValue, err := m.Unmarshaller.UnmarshalMap(value)
+		if err == nil {
+			return objectValue
+		}
+		err = m.unmarshalJson(value, &objectValue)
+		if err == nil {
+			return objectValue
+		}
+	case "array":
+		arrayValue, err := m.Unmarshaller.UnmarshalArray(value)
+		if err == nil {
+			return arrayValue
+		}
+		err = m.unmarshalJson(value, &arrayValue)
+		if err == nil {
+			return arrayValue
+		}
+	}
+
+	return value
+}
+
+// ConvertTypedValue converts the value to the type hint, returning an error if the value is not valid for the type.
+// Values without a type hint are converted to match the existing value.
+func (m CommonMapManipulator) ConvertTypedValue(existing any, value string, valueType ValueType) (any, error) {
+	switch valueType {
+	case ValueTypeString:
+		return value, nil
+	case ValueTypeInt:
+		integer, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return integer, nil
+		}
+		unsigned, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, errors.New("the value \"" + value + "\" is not an int")
+		}
+		return unsigned, nil
+	case ValueTypeFloat:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("the value \"" + value + "\" is not a float")
+		}
+		return number, nil
+	case ValueTypeBool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("the value \"" + value + "\" is not a bool")
+		}
+		return boolean, nil
+	case ValueTypeNull:
+		return nil, nil
+	case ValueTypeObject:
+		objectValue, err := m.Unmarshaller.UnmarshalMap(value)
+		if err == nil {
+			return objectValue, nil
+		}
+		err = m.unmarshalJson(value, &objectValue)
+		if err != nil {
+			return nil, errors.New("the value \"" + value + "\" is not an object")
+		}
+		return objectValue, nil
+	case ValueTypeArray:
+		arrayValue, err := m.Unmarshaller.UnmarshalArray(value)
+		if err == nil {
+			return arrayValue, nil
+		}
+		err = m.unmarshalJson(value, &arrayValue)
+		if err != nil {
+			return nil, errors.New("the value \"" + value + "\" is not an array")
+		}
+		return arrayValue, nil
+	case ValueTypeJson:
+		var jsonValue any
+		err := m.unmarshalJson(value, &jsonValue)
+		if err != nil {
+			return nil, errors.New("the value \"" + value + "\" is not valid JSON: " + err.Error())
+		}
+		return jsonValue, nil
+	}
+
+	return m.ConvertValue(existing, value), nil
+}
+
+// convertInteger parses the value as an integer of the same type as the existing value. The returned boolean is
+// false if the existing value is not an integer, or the value can not be stored in the existing type.
+func (m CommonMapManipulator) convertInteger(existing any, value string) (any, bool) {
+	if number, ok := existing.(json.Number); ok {
+		if strings.ContainsAny(number.String(), ".eE") {
+			return nil, false
+		}
+
+		// JSON integers can be any size, so the digits are kept rather than being parsed into an int64
+		integer, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, false
+		}
+		return json.Number(integer.String()), true
+	}
+
+	existingValue := reflect.ValueOf(existing)
+	if !existingValue.IsValid() {
+		return nil, false
+	}
+
+	result := reflect.New(existingValue.Type()).Elem()
+	switch existingValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || result.OverflowInt(integer) {
+			return nil, false
+		}
+		result.SetInt(integer)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		integer, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || result.OverflowUint(integer) {
+			return nil, false
+		}
+		result.SetUint(integer)
+	default:
+		return nil, false
+	}
+
+	return result.Interface(), true
+}
+
+// formatFloat formats a number replacing an existing json.Number. A whole number replacing a float like "1.5" is
+// written with a decimal point, like "3.0", so it remains a float in the same way as it does in TOML and YAML files.
+func (m CommonMapManipulator) formatFloat(existing json.Number, number float64) json.Number {
+	formatted := strconv.FormatFloat(number, 'g', -1, 64)
+
+	isWhole := !strings.ContainsAny(formatted, ".eE") && !math.IsInf(number, 0) && !math.IsNaN(number)
+	if strings.ContainsAny(existing.String(), ".eE") && isWhole {
+		formatted += ".0"
+	}
+
+	return json.Number(formatted)
+}
+
+// unmarshalJson parses JSON values supplied for objects and arrays. Integers are parsed as int64 rather than
+// float64 so they are written as integers, and large values do not lose precision.
+func (m CommonMapManipulator) unmarshalJson(value string, result any) error {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	err := decoder.Decode(result)
+	if err != nil {
+		return err
+	}
+
+	if decoder.More() {
+		return errors.New("unexpected content after the JSON value")
+	}
+
+	switch typedResult := result.(type) {
+	case *map[string]any:
+		m.convertNumbers(*typedResult)
+	case *[]any:
+		m.convertNumbers(*typedResult)
+	case *any:
+		*typedResult = m.convertNumbers(*typedResult)
+	}
+
+	return nil
+}
+
+// convertNumbers replaces json.Number values with int64, uint64, or float64 values.
+func (m CommonMapManipulator) convertNumbers(value any) any {
+	switch typedValue := value.(type) {
+	case json.Number:
+		if integer, err := typedValue.Int64(); err == nil {
+			return integer
+		}
+		if integer, err := strconv.ParseUint(typedValue.String(), 10, 64); err == nil {
+			return integer
+		}
+		if number, err := typedValue.Float64(); err == nil {
+			return number
+		}
+	case map[string]any:
+		for key, item := range typedValue {
+			typedValue[key] = m.convertNumbers(item)
+		}
+	case []any:
+		for index, item := range typedValue {
+			typedValue[index] = m.convertNumbers(item)
+		}
+	}
+
+	return value
+}
+
 func (m CommonMapManipulator) getType(object any) string {
+	if _, ok := object.(json.Number); ok {
+		return "number"
+	}
+
+	switch reflect.ValueOf(object).Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "number"
+	}
+
 	if _, ok := object.(int); ok {
 		return "number"
 	}
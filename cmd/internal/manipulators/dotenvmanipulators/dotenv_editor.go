@@ -0,0 +1,266 @@
+package dotenvmanipulators
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// keyPattern matches the variable names accepted by dotenv libraries.
+var keyPattern = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_.-]*$")
+
+// dotenvEntry records the location of a variable in the original file.
+type dotenvEntry struct {
+	key    string
+	export bool
+	// start and end are the offsets of the lines that define the variable, including the final line break. Quoted
+	// values may span many lines.
+	start int
+	end   int
+	// valueStart and valueEnd are the offsets of the value, including any quotes
+	valueStart int
+	valueEnd   int
+	// separator is the text between the key and the value e.g. " = "
+	separator string
+	// quote is the character the original value was wrapped in, if any
+	quote string
+}
+
+// dotenvEditor edits a dotenv file in place, so comments, blank lines, export prefixes, and the quoting of each value
+// are retained.
+type dotenvEditor struct {
+	content string
+	entries []dotenvEntry
+	newline string
+}
+
+func newDotenvEditor(content string) (*dotenvEditor, error) {
+	editor := dotenvEditor{
+		content: content,
+		newline: "\n",
+	}
+
+	if strings.Contains(content, "\r\n") {
+		editor.newline = "\r\n"
+	}
+
+	err := editor.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &editor, nil
+}
+
+func (e *dotenvEditor) String() string {
+	return e.content
+}
+
+func (e *dotenvEditor) parse() error {
+	e.entries = []dotenvEntry{}
+
+	for pos := 0; pos < len(e.content); {
+		start := pos
+		line := fmt.Sprint(strings.Count(e.content[:start], "\n") + 1)
+		lineEnd := e.lineEnd(pos)
+		text := strings.TrimRight(e.content[pos:lineEnd], "\r")
+
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			pos = e.nextLine(lineEnd)
+			continue
+		}
+
+		entry := dotenvEntry{start: start}
+		keyStart := pos + len(text) - len(trimmed)
+		if strings.HasPrefix(trimmed, "export ") || strings.HasPrefix(trimmed, "export\t") {
+			entry.export = true
+			keyStart = e.skipWhitespace(keyStart + len("export"))
+		}
+
+		keyEnd := keyStart
+		for keyEnd < lineEnd && !strings.ContainsRune("= \t\r", rune(e.content[keyEnd])) {
+			keyEnd++
+		}
+		entry.key = e.content[keyStart:keyEnd]
+		if !keyPattern.MatchString(entry.key) {
+			return errors.New("invalid variable name \"" + entry.key + "\" on line " + line)
+		}
+
+		entry.valueStart = e.skipWhitespace(keyEnd)
+		if entry.valueStart >= len(e.content) || e.content[entry.valueStart] != '=' {
+			return errors.New("expected an equals sign after \"" + entry.key + "\" on line " + line)
+		}
+		entry.valueStart = e.skipWhitespace(entry.valueStart + 1)
+		entry.separator = e.content[keyEnd:entry.valueStart]
+
+		if entry.valueStart < len(e.content) && strings.ContainsRune("\"'`", rune(e.content[entry.valueStart])) {
+			entry.quote = e.content[entry.valueStart : entry.valueStart+1]
+			closing := e.findClosingQuote(entry.valueStart+1, entry.quote)
+			if closing == -1 {
+				return errors.New("unterminated quoted value for \"" + entry.key + "\" on line " + line)
+			}
+			entry.valueEnd = closing + 1
+			lineEnd = e.lineEnd(entry.valueEnd)
+		} else {
+			entry.valueEnd = entry.valueStart + e.valueLength(e.content[entry.valueStart:lineEnd])
+		}
+
+		entry.end = e.nextLine(lineEnd)
+		e.entries = append(e.entries, entry)
+		pos = entry.end
+	}
+
+	return nil
+}
+
+// Value returns the decoded value of the last matching variable.
+func (e *dotenvEditor) Value(key string) (string, bool) {
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key != key {
+			continue
+		}
+
+		raw := e.content[entry.valueStart:entry.valueEnd]
+		switch entry.quote {
+		case "\"":
+			return e.unescape(raw[1 : len(raw)-1]), true
+		case "'", "`":
+			return raw[1 : len(raw)-1], true
+		default:
+			return raw, true
+		}
+	}
+
+	return "", false
+}
+
+// SetValue replaces the value of every matching variable, or adds the variable to the end of the file if it does
+// not exist.
+func (e *dotenvEditor) SetValue(key string, value string) error {
+	if !keyPattern.MatchString(key) {
+		return errors.New("invalid variable name \"" + key + "\"")
+	}
+
+	found := false
+
+	// Entries are replaced from the end of the file so the offsets of earlier entries are not changed
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key != key {
+			continue
+		}
+		found = true
+
+		e.content = e.content[:entry.valueStart] + e.formatValue(value, entry.quote) + e.content[entry.valueEnd:]
+	}
+
+	if !found {
+		prefix := ""
+		separator := "="
+		if len(e.entries) != 0 {
+			last := e.entries[len(e.entries)-1]
+			separator = last.separator
+			if last.export {
+				prefix = "export "
+			}
+		}
+
+		if e.content != "" && !strings.HasSuffix(e.content, "\n") {
+			e.content += e.newline
+		}
+		e.content += prefix + key + separator + e.formatValue(value, "") + e.newline
+	}
+
+	return e.parse()
+}
+
+// DeleteKey removes every matching variable.
+func (e *dotenvEditor) DeleteKey(key string) error {
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key == key {
+			e.content = e.content[:entry.start] + e.content[entry.end:]
+		}
+	}
+
+	return e.parse()
+}
+
+// formatValue quotes the value in the same style as the original value where possible. Unquoted values that
+// contain whitespace, quotes, or comment characters are wrapped in double quotes. Values that contain a dollar sign
+// are wrapped in single quotes, or have the dollar sign escaped in double quotes, so it is not expanded as a variable.
+func (e *dotenvEditor) formatValue(value string, quote string) string {
+	switch {
+	case quote == "'" && !strings.ContainsAny(value, "'\r\n"):
+		return quote + value + quote
+	case quote == "`" && !strings.ContainsAny(value, "`$\r\n"):
+		return quote + value + quote
+	case quote == "" && value != "" && !strings.ContainsAny(value, " \t\r\n#\"'`\\$"):
+		return value
+	case strings.Contains(value, "$") && !strings.ContainsAny(value, "'\r\n"):
+		return "'" + value + "'"
+	default:
+		replacer := strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "\r", "\\r", "\n", "\\n")
+		return "\"" + replacer.Replace(value) + "\""
+	}
+}
+
+// unescape decodes the escape sequences in a double quoted value.
+func (e *dotenvEditor) unescape(value string) string {
+	replacer := strings.NewReplacer("\\\\", "\\", "\\\"", "\"", "\\$", "$", "\\r", "\r", "\\n", "\n", "\\t", "\t")
+	return replacer.Replace(value)
+}
+
+// findClosingQuote returns the offset of the quote that closes a value, or -1 if the value is not closed. Double
+// quoted values may contain escaped quotes.
+func (e *dotenvEditor) findClosingQuote(pos int, quote string) int {
+	for i := pos; i < len(e.content); i++ {
+		if e.content[i] == '\\' && quote == "\"" {
+			i++
+		} else if e.content[i] == quote[0] {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// valueLength returns the length of an unquoted value, excluding any inline comment and trailing whitespace.
+func (e *dotenvEditor) valueLength(value string) int {
+	end := len(value)
+	for i := 1; i < len(value); i++ {
+		if value[i] == '#' && (value[i-1] == ' ' || value[i-1] == '\t') {
+			end = i
+			break
+		}
+	}
+
+	return len(strings.TrimRight(value[:end], " \t\r"))
+}
+
+func (e *dotenvEditor) lineEnd(pos int) int {
+	if index := strings.Index(e.content[pos:], "\n"); index != -1 {
+		return pos + index
+	}
+
+	return len(e.content)
+}
+
+func (e *dotenvEditor) nextLine(lineEnd int) int {
+	if lineEnd >= len(e.content) {
+		return len(e.content)
+	}
+
+	return lineEnd + 1
+}
+
+func (e *dotenvEditor) skipWhitespace(pos int) int {
+	for pos < len(e.content) && (e.content[pos] == ' ' || e.content[pos] == '\t') {
+		pos++
+	}
+
+	return pos
+}
@@ -0,0 +1,107 @@
+package dotenvmanipulators
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"path/filepath"
+	"strings"
+)
+
+// DotenvManipulator edits dotenv files, like those read by Node and Laravel applications. Dotenv files are flat, so
+// the accessor is the name of the variable e.g. "DB_HOST".
+type DotenvManipulator struct {
+	Writer writers.Writer
+	Reader readers.Reader
+}
+
+func (m DotenvManipulator) GetFormatName() string {
+	return "Dotenv"
+}
+
+func (m DotenvManipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m DotenvManipulator) MatchesFileName(fileSpec string) bool {
+	return m.isDotenvFile(fileSpec)
+}
+
+func (m DotenvManipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m DotenvManipulator) ParseError(fileSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	_, err = newDotenvEditor(content)
+	return err
+}
+
+// isDotenvFile matches files like ".env", ".env.local", and "production.env".
+func (m DotenvManipulator) isDotenvFile(fileSpec string) bool {
+	name := filepath.Base(fileSpec)
+	return name == ".env" || strings.HasPrefix(name, ".env.") || strings.HasSuffix(name, ".env")
+}
+
+func (m DotenvManipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
+	if err := manipulators.ValidateTextValue(m.GetFormatName(), value, valueType); err != nil {
+		return err
+	}
+
+	return m.SetValue(fileSpec, valueSpec, value)
+}
+
+func (m DotenvManipulator) SetValue(fileSpec string, valueSpec string, value string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	editor, err := newDotenvEditor(content)
+	if err != nil {
+		return err
+	}
+
+	key := m.getKey(valueSpec)
+	if key == "" {
+		return errors.New("path must include a key to set")
+	}
+
+	err = editor.SetValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	return m.Writer.WriteString(fileSpec, editor.String())
+}
+
+func (m DotenvManipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	editor, err := newDotenvEditor(content)
+	if err != nil {
+		return err
+	}
+
+	err = editor.DeleteKey(m.getKey(valueSpec))
+	if err != nil {
+		return err
+	}
+
+	return m.Writer.WriteString(fileSpec, editor.String())
+}
+
+// getKey returns the variable name. Dotenv files are flat, so colons do not separate path elements.
+func (m DotenvManipulator) getKey(valueSpec string) string {
+	return manipulators.PathKey(stringutil.Unescape(valueSpec))
+}
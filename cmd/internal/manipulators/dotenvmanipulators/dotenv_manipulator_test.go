@@ -0,0 +1,158 @@
+package dotenvmanipulators
+
+import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+	"testing"
+)
+
+func TestDotenvFileNames(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/var/www/.env":            "APP_ENV=local",
+			"/var/www/.env.production": "APP_ENV=production",
+			"/var/www/app.env":         "APP_ENV=local",
+			"/var/www/config.ini":      "APP_ENV=local",
+			"/var/www/invalid/.env":    "APP ENV=local",
+		},
+	}
+	manipulator := DotenvManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	for _, file := range []string{"/var/www/.env", "/var/www/.env.production", "/var/www/app.env"} {
+		if !manipulator.CanManipulate(file) {
+			t.Fatal("Must be able to manipulate " + file)
+		}
+	}
+
+	for _, file := range []string{"/var/www/config.ini", "/var/www/invalid/.env"} {
+		if manipulator.CanManipulate(file) {
+			t.Fatal("Must not be able to manipulate " + file)
+		}
+	}
+}
+
+func TestDotenvSetPreservesFormatting(t *testing.T) {
+	dotenvExample := "# Application\n" +
+		"APP_NAME=\"My App\"\n" +
+		"export APP_KEY='base64:abc'\n" +
+		"\n" +
+		"DB_HOST=localhost # the database server\n" +
+		"DB_PASSWORD=\n" +
+		"PRIVATE_KEY=\"-----BEGIN KEY-----\n" +
+		"abc\n" +
+		"-----END KEY-----\"\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/var/www/.env": dotenvExample,
+		},
+	}
+	manipulator := DotenvManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"APP_NAME", "Say \"hi\"", strings.Replace(dotenvExample, "\"My App\"", "\"Say \\\"hi\\\"\"", 1)},
+		{"APP_KEY", "base64:xyz", strings.Replace(dotenvExample, "'base64:abc'", "'base64:xyz'", 1)},
+		{"DB_HOST", "db.example.org", strings.Replace(dotenvExample, "localhost", "db.example.org", 1)},
+		{"DB_HOST", "my db", strings.Replace(dotenvExample, "localhost", "\"my db\"", 1)},
+		{"DB_PASSWORD", "secret", strings.Replace(dotenvExample, "DB_PASSWORD=", "DB_PASSWORD=secret", 1)},
+		{"PRIVATE_KEY", "none", strings.Replace(dotenvExample, "\"-----BEGIN KEY-----\nabc\n-----END KEY-----\"", "\"none\"", 1)},
+		{"APP_KEY", "it's $x \"q\"", strings.Replace(dotenvExample, "'base64:abc'", "\"it's \\$x \\\"q\\\"\"", 1)},
+		{"DB_HOST", "p$ss", strings.Replace(dotenvExample, "localhost", "'p$ss'", 1)},
+		{"CACHE_DRIVER", "redis", dotenvExample + "CACHE_DRIVER=redis\n"},
+		{"DB_PASS", "p$ss", dotenvExample + "DB_PASS='p$ss'\n"},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/var/www/.env", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate dotenv file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/var/www/.env"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the matching line (was: \"" + output + "\")")
+		}
+
+		editor, err := newDotenvEditor(output)
+
+		if err != nil {
+			t.Fatal("Failed to parse the modified dotenv file: " + err.Error())
+		}
+
+		if value, ok := editor.Value(test.accessor); !ok || value != test.value {
+			t.Fatal("The modified file must contain the new value for " + test.accessor + " (was: \"" + value + "\")")
+		}
+	}
+}
+
+func TestDotenvSetExportedVariable(t *testing.T) {
+	dotenvExample := "export NODE_ENV=development"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/app/.env": dotenvExample,
+		},
+	}
+	manipulator := DotenvManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.SetValue("/app/.env", "PORT", "3000")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate dotenv file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/app/.env"]
+
+	if output != "export NODE_ENV=development\nexport PORT=3000\n" {
+		t.Fatal("New variables must use the same export prefix as the existing variables (was: \"" + output + "\")")
+	}
+
+	err = manipulator.SetValue("/app/.env", "INVALID NAME", "3000")
+
+	if err == nil {
+		t.Fatal("Invalid variable names must be rejected")
+	}
+}
+
+func TestDotenvDeletePreservesFormatting(t *testing.T) {
+	dotenvExample := "# Database\nDB_HOST=localhost\nDB_CERT=\"line1\nline2\"\nDB_PORT=3306\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/var/www/.env": dotenvExample,
+		},
+	}
+	manipulator := DotenvManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.DeleteValue("/var/www/.env", "DB_CERT")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate dotenv file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/var/www/.env"]
+
+	if output != "# Database\nDB_HOST=localhost\nDB_PORT=3306\n" {
+		t.Fatal("The variable and all of its lines must be deleted (was: \"" + output + "\")")
+	}
+}
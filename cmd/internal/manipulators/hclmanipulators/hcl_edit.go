@@ -0,0 +1,208 @@
+package hclmanipulators
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"sort"
+	"strings"
+)
+
+// hclEdit describes the change made to a body, so that only the changed attribute or block is written back to the
+// original content. Writing the whole file with hclwrite would reformat it in the canonical HCL style.
+type hclEdit struct {
+	// body is the body that was changed
+	body *hclwrite.Body
+	// attribute is the name of the attribute that was set or removed
+	attribute string
+	// block is a new block appended to the body
+	block *hclwrite.Block
+	// removed are the indexes of the blocks removed from the body
+	removed []int
+}
+
+// applyEdit writes the change described by the edit to the original content. Attributes that are set have their
+// expression replaced, new attributes and blocks are added to the end of their body, and removed attributes and blocks
+// are removed along with the comments immediately above them.
+func applyEdit(fileSpec string, content string, file *hclwrite.File, edit *hclEdit) string {
+	if edit == nil {
+		return content
+	}
+
+	parsed, diags := hclsyntax.ParseConfig([]byte(content), fileSpec, hcl.InitialPos)
+	if diags.HasErrors() {
+		return string(file.Bytes())
+	}
+
+	root := parsed.Body.(*hclsyntax.Body)
+	body, block, found := findSyntaxBody(file.Body(), root, nil, edit.body)
+	if !found {
+		return string(file.Bytes())
+	}
+
+	if edit.block != nil {
+		text := formatTokens(edit.block.BuildTokens(nil))
+		if len(body.Attributes) != 0 || len(body.Blocks) != 0 {
+			text = "\n" + text
+		}
+		return insertItem(content, body, block, text)
+	}
+
+	if edit.attribute != "" {
+		existing := body.Attributes[edit.attribute]
+		attribute := edit.body.GetAttribute(edit.attribute)
+
+		if existing == nil {
+			return insertItem(content, body, block, formatTokens(attribute.BuildTokens(nil)))
+		}
+
+		if attribute == nil {
+			return removeItem(content, existing.SrcRange, block)
+		}
+
+		// Expressions written over several lines, like objects, are indented to match the attribute
+		expression := existing.Expr.Range()
+		text := strings.TrimSuffix(formatTokens(attribute.Expr().BuildTokens(nil)), "\n")
+		text = strings.ReplaceAll(text, "\n", "\n"+lineIndent(content, existing.SrcRange.Start.Byte))
+		return content[:expression.Start.Byte] + text + content[expression.End.Byte:]
+	}
+
+	// Blocks are removed from the end of the content first, so the positions of the earlier blocks do not change
+	sort.Sort(sort.Reverse(sort.IntSlice(edit.removed)))
+	for _, index := range edit.removed {
+		content = removeItem(content, body.Blocks[index].Range(), block)
+	}
+
+	return content
+}
+
+// findSyntaxBody returns the parsed body matching the edited body, along with the block that contains it, or nil for the
+// root body. Blocks in both bodies are in the order they appear in the file.
+func findSyntaxBody(edited *hclwrite.Body, parsed *hclsyntax.Body, block *hclsyntax.Block, target *hclwrite.Body) (*hclsyntax.Body, *hclsyntax.Block, bool) {
+	if edited == target {
+		return parsed, block, true
+	}
+
+	for i, child := range edited.Blocks() {
+		if i >= len(parsed.Blocks) {
+			break
+		}
+
+		if body, parent, found := findSyntaxBody(child.Body(), parsed.Blocks[i].Body, parsed.Blocks[i], target); found {
+			return body, parent, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// insertItem adds the text of a new attribute or block to the end of a body. The root body is added to at the end of
+// the file, and block bodies are added to on the line before the closing brace.
+func insertItem(content string, body *hclsyntax.Body, block *hclsyntax.Block, text string) string {
+	if block == nil {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + text
+	}
+
+	closeBrace := block.CloseBraceRange.Start.Byte
+	closeIndent := lineIndent(content, closeBrace)
+	indent := closeIndent + "  "
+	if start, ok := firstItemStart(body); ok && lineStart(content, start) > block.OpenBraceRange.Start.Byte {
+		indent = lineIndent(content, start)
+	}
+
+	if lineStart(content, closeBrace)+len(closeIndent) == closeBrace {
+		position := lineStart(content, closeBrace)
+		return content[:position] + indentLines(text, indent) + content[position:]
+	}
+
+	// Blocks written on a single line, like `telemetry {}`, are written over several lines to hold the new item
+	interior := "\n"
+	for _, attribute := range body.Attributes {
+		interior += indent + content[attribute.SrcRange.Start.Byte:attribute.SrcRange.End.Byte] + "\n"
+	}
+	interior += indentLines(strings.TrimPrefix(text, "\n"), indent) + closeIndent
+
+	return content[:block.OpenBraceRange.End.Byte] + interior + content[closeBrace:]
+}
+
+// removeItem removes the lines holding an attribute or block, along with any comments on the lines immediately above.
+// Attributes in a block written on a single line, like `tls { enabled = true }`, are removed from the line instead.
+func removeItem(content string, itemRange hcl.Range, block *hclsyntax.Block) string {
+	start := lineStart(content, itemRange.Start.Byte)
+	end := itemRange.End.Byte
+	rest := content[end:]
+	if newline := strings.Index(rest, "\n"); newline != -1 {
+		rest = rest[:newline+1]
+	}
+
+	trailing := strings.TrimSpace(rest)
+	if strings.TrimSpace(content[start:itemRange.Start.Byte]) != "" || (trailing != "" && !isComment(trailing)) {
+		if block != nil && itemRange.Start.Byte > block.OpenBraceRange.End.Byte && itemRange.End.Byte < block.CloseBraceRange.Start.Byte {
+			return content[:block.OpenBraceRange.End.Byte] + content[block.CloseBraceRange.Start.Byte:]
+		}
+		return content[:itemRange.Start.Byte] + content[itemRange.End.Byte:]
+	}
+
+	for start > 0 {
+		previous := lineStart(content, start-1)
+		if !isComment(strings.TrimSpace(content[previous:start])) {
+			break
+		}
+		start = previous
+	}
+
+	return content[:start] + content[end+len(rest):]
+}
+
+// formatTokens returns the tokens written in the canonical HCL style.
+func formatTokens(tokens hclwrite.Tokens) string {
+	return string(hclwrite.Format(tokens.Bytes()))
+}
+
+// firstItemStart returns the position of the first attribute or block in a body.
+func firstItemStart(body *hclsyntax.Body) (int, bool) {
+	start := -1
+	for _, attribute := range body.Attributes {
+		if start == -1 || attribute.SrcRange.Start.Byte < start {
+			start = attribute.SrcRange.Start.Byte
+		}
+	}
+
+	for _, block := range body.Blocks {
+		if start == -1 || block.TypeRange.Start.Byte < start {
+			start = block.TypeRange.Start.Byte
+		}
+	}
+
+	return start, start != -1
+}
+
+// lineStart returns the position of the start of the line holding the position.
+func lineStart(content string, position int) int {
+	return strings.LastIndex(content[:position], "\n") + 1
+}
+
+// lineIndent returns the whitespace at the start of the line holding the position.
+func lineIndent(content string, position int) string {
+	line := content[lineStart(content, position):]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// indentLines adds the indent to the start of every line that is not empty.
+func indentLines(text string, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//")
+}
@@ -0,0 +1,319 @@
+package hclmanipulators
+
+import (
+	"errors"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+)
+
+// hclExtensions are the file extensions used by HashiCorp tools that read HCL configuration.
+var hclExtensions = []string{".hcl", ".nomad", ".tf", ".tfvars"}
+
+// HclManipulator edits HCL files, like those used by Vault, Consul, Nomad, and Terraform. Accessors address blocks
+// by their type and labels, followed by the attribute to set e.g. "listener:tcp:address" sets the address attribute
+// in the block starting with `listener "tcp"`. Files are edited in place, so comments and formatting are retained.
+type HclManipulator struct {
+	Writer         writers.Writer
+	Reader         readers.Reader
+	MapManipulator manipulators.MapManipulator
+}
+
+func (m HclManipulator) GetFormatName() string {
+	return "HCL"
+}
+
+func (m HclManipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m HclManipulator) MatchesFileName(fileSpec string) bool {
+	return m.hasHclExtension(fileSpec)
+}
+
+func (m HclManipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m HclManipulator) ParseError(fileSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.parse(fileSpec, content)
+	return err
+}
+
+func (m HclManipulator) SetValue(fileSpec string, valueSpec string, value string) error {
+	return m.SetTypedValue(fileSpec, valueSpec, value, manipulators.ValueTypeAuto)
+}
+
+func (m HclManipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	file, err := m.parse(fileSpec, content)
+	if err != nil {
+		return err
+	}
+
+	edit, err := m.setValue(file.Body(), manipulators.SplitPath(valueSpec), value, valueType)
+	if err != nil {
+		return err
+	}
+
+	return m.Writer.WriteString(fileSpec, applyEdit(fileSpec, content, file, edit))
+}
+
+func (m HclManipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	file, err := m.parse(fileSpec, content)
+	if err != nil {
+		return err
+	}
+
+	edit, err := m.deleteValue(file.Body(), manipulators.SplitPath(valueSpec))
+	if err != nil {
+		return err
+	}
+
+	return m.Writer.WriteString(fileSpec, applyEdit(fileSpec, content, file, edit))
+}
+
+func (m HclManipulator) parse(fileSpec string, content string) (*hclwrite.File, error) {
+	file, diags := hclwrite.ParseConfig([]byte(content), fileSpec, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return file, nil
+}
+
+func (m HclManipulator) hasHclExtension(fileSpec string) bool {
+	for _, extension := range hclExtensions {
+		if strings.HasSuffix(fileSpec, extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setValue walks the path through nested blocks until it reaches an attribute. Missing blocks are created, with the
+// path elements between the block type and the attribute used as the block labels.
+func (m HclManipulator) setValue(body *hclwrite.Body, path []string, value string, valueType manipulators.ValueType) (*hclEdit, error) {
+	name := manipulators.PathKey(path[0])
+	if name == "" {
+		return nil, errors.New("path must include an attribute to set")
+	}
+
+	if len(path) == 1 || body.GetAttribute(name) != nil {
+		return &hclEdit{body: body, attribute: name}, m.setAttribute(body, name, path[1:], value, valueType)
+	}
+
+	block, remaining, err := m.findBlock(body, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block == nil {
+		labels := []string{}
+		for _, p := range path[1 : len(path)-1] {
+			labels = append(labels, manipulators.PathKey(p))
+		}
+
+		// The new block is written with the nested blocks and attribute it holds
+		block = body.AppendNewBlock(name, labels)
+		if _, err := m.setValue(block.Body(), path[len(path)-1:], value, valueType); err != nil {
+			return nil, err
+		}
+
+		return &hclEdit{body: body, block: block}, nil
+	}
+
+	return m.setValue(block.Body(), remaining, value, valueType)
+}
+
+// setAttribute sets the value of an attribute, or a value nested inside an attribute holding an object or a list.
+// The new value retains the type of the existing value unless a type hint is supplied.
+func (m HclManipulator) setAttribute(body *hclwrite.Body, name string, path []string, value string, valueType manipulators.ValueType) error {
+	var existing any
+	isLiteral := true
+	if attribute := body.GetAttribute(name); attribute != nil {
+		existing, isLiteral = m.evaluate(attribute)
+	}
+
+	var converted any
+	if len(path) == 0 {
+		result, err := m.MapManipulator.ConvertTypedValue(existing, value, valueType)
+		if err != nil {
+			return err
+		}
+		converted = result
+	} else {
+		// Expressions like variable references and function calls can not be evaluated without the context
+		// supplied by the application reading the file
+		if !isLiteral {
+			return errors.New("the attribute \"" + name + "\" is an expression, so values nested inside it can not be set")
+		}
+
+		result, err := m.MapManipulator.ProcessMap(map[string]any{name: existing}, manipulators.JoinPath(append([]string{"\"" + name + "\""}, path...)), value, valueType)
+		if err != nil {
+			return err
+		}
+		converted = result[name]
+	}
+
+	ctyValue, err := toCty(converted)
+	if err != nil {
+		return err
+	}
+
+	body.SetAttributeValue(name, ctyValue)
+	return nil
+}
+
+// deleteValue removes the attribute or block at the end of the path. Paths that do not exist are ignored, so deleting
+// a value is safe to repeat.
+func (m HclManipulator) deleteValue(body *hclwrite.Body, path []string) (*hclEdit, error) {
+	name := manipulators.PathKey(path[0])
+
+	if body.GetAttribute(name) != nil {
+		if len(path) == 1 {
+			body.RemoveAttribute(name)
+			return &hclEdit{body: body, attribute: name}, nil
+		}
+
+		return &hclEdit{body: body, attribute: name}, m.deleteFromAttribute(body, name, path[1:])
+	}
+
+	// A path that ends with the block type and labels removes the matching blocks
+	removed := []int{}
+	for i, block := range body.Blocks() {
+		if block.Type() == name && len(block.Labels()) == len(path)-1 && m.labelsMatch(block.Labels(), path[1:]) {
+			body.RemoveBlock(block)
+			removed = append(removed, i)
+		}
+	}
+
+	if len(removed) != 0 {
+		return &hclEdit{body: body, removed: removed}, nil
+	}
+
+	if len(path) == 1 {
+		return nil, nil
+	}
+
+	block, remaining, err := m.findBlock(body, path)
+	if err != nil || block == nil {
+		// There is no block to delete from
+		return nil, nil
+	}
+
+	return m.deleteValue(block.Body(), remaining)
+}
+
+// deleteFromAttribute removes a value nested inside an attribute holding an object or a list.
+func (m HclManipulator) deleteFromAttribute(body *hclwrite.Body, name string, path []string) error {
+	existing, isLiteral := m.evaluate(body.GetAttribute(name))
+	if !isLiteral {
+		return errors.New("the attribute \"" + name + "\" is an expression, so values nested inside it can not be deleted")
+	}
+
+	result, err := m.MapManipulator.DeleteFromMap(map[string]any{name: existing}, manipulators.JoinPath(append([]string{"\"" + name + "\""}, path...)))
+	if err != nil {
+		return err
+	}
+
+	ctyValue, err := toCty(result[name])
+	if err != nil {
+		return err
+	}
+
+	body.SetAttributeValue(name, ctyValue)
+	return nil
+}
+
+// findBlock returns the block matching the type and labels at the start of the path, along with the remaining path
+// elements. Blocks that are repeated with the same labels can be selected by their index after the block type e.g.
+// "service:1:port". A nil block is returned if there is no matching block.
+func (m HclManipulator) findBlock(body *hclwrite.Body, path []string) (*hclwrite.Block, []string, error) {
+	name := manipulators.PathKey(path[0])
+
+	blocks := []*hclwrite.Block{}
+	for _, block := range body.Blocks() {
+		if block.Type() == name {
+			blocks = append(blocks, block)
+		}
+	}
+
+	// Labels are matched first, so a label like "0" is not mistaken for an index
+	if block, remaining := m.matchLabels(blocks, path, true); block != nil {
+		return block, remaining, nil
+	}
+
+	if len(blocks) != 0 && len(path) > 2 && manipulators.IsArrayIndex(path[1]) {
+		if manipulators.IsArrayInsert(path[1]) {
+			return nil, nil, errors.New("append and insert indexes can not be used to select blocks (path element \"" + path[1] + "\")")
+		}
+
+		index, _, err := manipulators.ResolveArrayIndex(path[1], len(blocks))
+		if err != nil {
+			return nil, nil, errors.New(err.Error() + " when selecting a \"" + name + "\" block")
+		}
+
+		return blocks[index], path[2:], nil
+	}
+
+	block, remaining := m.matchLabels(blocks, path, false)
+	return block, remaining, nil
+}
+
+// matchLabels returns the first block whose labels match the path elements after the block type. At least one path
+// element must remain after the labels to address an attribute or nested block.
+func (m HclManipulator) matchLabels(blocks []*hclwrite.Block, path []string, labelled bool) (*hclwrite.Block, []string) {
+	for _, block := range blocks {
+		labels := block.Labels()
+		if (len(labels) != 0) == labelled && len(labels) < len(path)-1 && m.labelsMatch(labels, path[1:]) {
+			return block, path[1+len(labels):]
+		}
+	}
+
+	return nil, path
+}
+
+func (m HclManipulator) labelsMatch(labels []string, path []string) bool {
+	if len(labels) > len(path) {
+		return false
+	}
+
+	for i, label := range labels {
+		if label != manipulators.PathKey(path[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// evaluate returns the value of an attribute. The returned boolean is false if the attribute is an expression that
+// can not be evaluated without variables or functions, like "var.port" or "file(\"cert.pem\")".
+func (m HclManipulator) evaluate(attribute *hclwrite.Attribute) (any, bool) {
+	value, err := parseExpression(attribute.Expr().BuildTokens(nil).Bytes())
+	if err != nil {
+		return nil, false
+	}
+
+	return fromCty(value), true
+}
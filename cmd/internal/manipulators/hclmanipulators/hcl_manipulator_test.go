@@ -0,0 +1,270 @@
+package hclmanipulators
+
+import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+	"testing"
+)
+
+const vaultExample = "# Vault server configuration\n" +
+	"\n" +
+	"ui = true\n" +
+	"\n" +
+	"listener \"tcp\" {\n" +
+	"  address     = \"0.0.0.0:8200\" # bind to all interfaces\n" +
+	"  tls_disable = 1\n" +
+	"}\n" +
+	"\n" +
+	"storage \"raft\" {\n" +
+	"  path    = \"/vault/data\"\n" +
+	"  node_id = \"node1\"\n" +
+	"\n" +
+	"  retry_join {\n" +
+	"    leader_api_addr = \"http://vault1:8200\"\n" +
+	"  }\n" +
+	"\n" +
+	"  retry_join {\n" +
+	"    leader_api_addr = \"http://vault2:8200\"\n" +
+	"  }\n" +
+	"}\n" +
+	"\n" +
+	"seal \"transit\" {\n" +
+	"  address = var.vault_addr\n" +
+	"  meta = {\n" +
+	"    owner = \"ops\"\n" +
+	"  }\n" +
+	"}\n"
+
+func newTestManipulator(files map[string]string) (HclManipulator, *writers.StringWriter) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &files,
+	}
+	manipulator := HclManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: HclUnmarshaller{},
+		},
+	}
+
+	return manipulator, &writer
+}
+
+func TestHclFileNames(t *testing.T) {
+	manipulator, _ := newTestManipulator(map[string]string{
+		"/etc/vault.d/vault.hcl":  vaultExample,
+		"/etc/nomad.d/job.nomad":  "job \"web\" {}",
+		"/app/main.tf":            "variable \"region\" {}",
+		"/app/prod.tfvars":        "region = \"us-east-1\"",
+		"/etc/vault.d/vault.json": "{\"ui\": true}",
+		"/etc/vault.d/bad.hcl":    "listener \"tcp\" {",
+	})
+
+	for _, file := range []string{"/etc/vault.d/vault.hcl", "/etc/nomad.d/job.nomad", "/app/main.tf", "/app/prod.tfvars"} {
+		if !manipulator.CanManipulate(file) {
+			t.Fatal("Must be able to manipulate " + file)
+		}
+	}
+
+	for _, file := range []string{"/etc/vault.d/vault.json", "/etc/vault.d/bad.hcl"} {
+		if manipulator.CanManipulate(file) {
+			t.Fatal("Must not be able to manipulate " + file)
+		}
+	}
+}
+
+func TestHclSetPreservesFormatting(t *testing.T) {
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"ui", "false", strings.Replace(vaultExample, "ui = true", "ui = false", 1)},
+		{"listener:tcp:address", "127.0.0.1:8200", strings.Replace(vaultExample, "\"0.0.0.0:8200\"", "\"127.0.0.1:8200\"", 1)},
+		{"listener:tcp:tls_disable", "0", strings.Replace(vaultExample, "tls_disable = 1", "tls_disable = 0", 1)},
+		{"storage:raft:retry_join:1:leader_api_addr", "http://vault3:8200", strings.Replace(vaultExample, "vault2", "vault3", 1)},
+		{"storage:raft:retry_join:-1:leader_api_addr", "http://vault3:8200", strings.Replace(vaultExample, "vault2", "vault3", 1)},
+		{"seal:transit:address", "https://vault:8200", strings.Replace(vaultExample, "var.vault_addr", "\"https://vault:8200\"", 1)},
+		{"seal:transit:meta:owner", "dev", strings.Replace(vaultExample, "owner = \"ops\"", "owner = \"dev\"", 1)},
+	}
+
+	for _, test := range tests {
+		manipulator, writer := newTestManipulator(map[string]string{"/etc/vault.d/vault.hcl": vaultExample})
+
+		err := manipulator.SetValue("/etc/vault.d/vault.hcl", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate HCL file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/vault.d/vault.hcl"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the matching attribute (was: \"" + output + "\")")
+		}
+	}
+}
+
+func TestHclSetRetainsLayout(t *testing.T) {
+	layout := "x = var.y\nlonger_name = 1\nmeta = { owner = \"a\" }\n\ntabbed {\n\tkey = 1\n}\n\ntls { enabled = true }\n"
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"longer_name", "2", strings.Replace(layout, "longer_name = 1", "longer_name = 2", 1)},
+		{"meta:owner", "b", strings.Replace(layout, "{ owner = \"a\" }", "{\n  owner = \"b\"\n}", 1)},
+		{"tabbed:name", "c", strings.Replace(layout, "\tkey = 1\n", "\tkey = 1\n\tname = \"c\"\n", 1)},
+		{"tls:cert", "d", strings.Replace(layout, "tls { enabled = true }", "tls {\n  enabled = true\n  cert = \"d\"\n}", 1)},
+	}
+
+	for _, test := range tests {
+		manipulator, writer := newTestManipulator(map[string]string{"/app/main.tf": layout})
+
+		err := manipulator.SetValue("/app/main.tf", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate HCL file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/app/main.tf"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the matching attribute (was: \"" + output + "\")")
+		}
+	}
+
+	manipulator, writer := newTestManipulator(map[string]string{"/app/main.tf": layout})
+
+	err := manipulator.DeleteValue("/app/main.tf", "tls:enabled")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate HCL file: " + err.Error())
+	}
+
+	if output := (*writer.Output)["/app/main.tf"]; output != strings.Replace(layout, "tls { enabled = true }", "tls {}", 1) {
+		t.Fatal("Deleting tls:enabled must only remove the matching attribute (was: \"" + output + "\")")
+	}
+}
+
+func TestHclSetNewValues(t *testing.T) {
+	manipulator, writer := newTestManipulator(map[string]string{"/etc/vault.d/vault.hcl": vaultExample})
+
+	for _, setting := range [][]string{
+		{"api_addr", "https://vault:8200"},
+		{"listener:tcp:tls_cert_file", "/vault/cert.pem"},
+		{"listener:unix:address", "/run/vault.sock"},
+		{"telemetry:disable_hostname", "true"},
+	} {
+		err := manipulator.SetValue("/etc/vault.d/vault.hcl", setting[0], setting[1])
+
+		if err != nil {
+			t.Fatal("Failed to manipulate HCL file: " + err.Error())
+		}
+
+		manipulator, _ = newTestManipulator(map[string]string{"/etc/vault.d/vault.hcl": (*writer.Output)["/etc/vault.d/vault.hcl"]})
+		manipulator.Writer = writer
+	}
+
+	output := (*writer.Output)["/etc/vault.d/vault.hcl"]
+
+	if !strings.Contains(output, "  tls_disable = 1\n  tls_cert_file = \"/vault/cert.pem\"\n}") {
+		t.Fatal("New attributes must be added to the existing block (was: \"" + output + "\")")
+	}
+
+	if !strings.Contains(output, "api_addr = \"https://vault:8200\"\n") {
+		t.Fatal("New top level attributes must be added (was: \"" + output + "\")")
+	}
+
+	if !strings.HasSuffix(output, "\n\nlistener \"unix\" {\n  address = \"/run/vault.sock\"\n}\n\ntelemetry {\n  disable_hostname = \"true\"\n}\n") {
+		t.Fatal("New blocks must be added with their labels (was: \"" + output + "\")")
+	}
+}
+
+func TestHclSetTypedValue(t *testing.T) {
+	tests := []struct {
+		accessor  string
+		value     string
+		valueType manipulators.ValueType
+		expected  string
+	}{
+		{"telemetry:disable_hostname", "true", manipulators.ValueTypeBool, "disable_hostname = true"},
+		{"listener:tcp:tls_disable", "true", manipulators.ValueTypeBool, "tls_disable = true"},
+		{"listener:tcp:address", "8200", manipulators.ValueTypeString, "\"8200\""},
+		{"max_lease_ttl", "768", manipulators.ValueTypeInt, "max_lease_ttl = 768"},
+		{"seal:transit:meta", "{owner = \"dev\", team = 2}", manipulators.ValueTypeObject, "team  = 2"},
+		{"plugin_directories", "[\"/a\", \"/b\"]", manipulators.ValueTypeArray, "plugin_directories = [\"/a\", \"/b\"]"},
+		{"plugin_directories", "[\"/a\", \"/b\"]", manipulators.ValueTypeJson, "plugin_directories = [\"/a\", \"/b\"]"},
+		{"cluster_name", "", manipulators.ValueTypeNull, "cluster_name = null"},
+	}
+
+	for _, test := range tests {
+		manipulator, writer := newTestManipulator(map[string]string{"/etc/vault.d/vault.hcl": vaultExample})
+
+		err := manipulator.SetTypedValue("/etc/vault.d/vault.hcl", test.accessor, test.value, test.valueType)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate HCL file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/vault.d/vault.hcl"]
+
+		if !strings.Contains(output, test.expected) {
+			t.Fatal("Setting " + test.accessor + " must write \"" + test.expected + "\" (was: \"" + output + "\")")
+		}
+	}
+
+	manipulator, _ := newTestManipulator(map[string]string{"/etc/vault.d/vault.hcl": vaultExample})
+
+	err := manipulator.SetTypedValue("/etc/vault.d/vault.hcl", "listener:tcp:tls_disable", "yes", manipulators.ValueTypeBool)
+
+	if err == nil {
+		t.Fatal("Values that do not match the type hint must be rejected")
+	}
+}
+
+func TestHclSetInvalidPaths(t *testing.T) {
+	for _, accessor := range []string{"seal:transit:address:scheme", "storage:raft:retry_join:5:leader_api_addr", "storage:raft:retry_join:-:leader_api_addr"} {
+		manipulator, _ := newTestManipulator(map[string]string{"/etc/vault.d/vault.hcl": vaultExample})
+
+		err := manipulator.SetValue("/etc/vault.d/vault.hcl", accessor, "value")
+
+		if err == nil {
+			t.Fatal("Setting " + accessor + " must fail")
+		}
+	}
+}
+
+func TestHclDeleteValue(t *testing.T) {
+	tests := []struct {
+		accessor string
+		expected string
+	}{
+		{"ui", strings.Replace(vaultExample, "ui = true\n", "", 1)},
+		{"listener:tcp:tls_disable", strings.Replace(vaultExample, "  tls_disable = 1\n", "", 1)},
+		{"listener:tcp", strings.Replace(vaultExample, "listener \"tcp\" {\n  address     = \"0.0.0.0:8200\" # bind to all interfaces\n  tls_disable = 1\n}\n", "", 1)},
+		{"storage:raft:retry_join:0:leader_api_addr", strings.Replace(vaultExample, "    leader_api_addr = \"http://vault1:8200\"\n", "", 1)},
+		{"listener:unix:address", vaultExample},
+		{"missing", vaultExample},
+	}
+
+	for _, test := range tests {
+		manipulator, writer := newTestManipulator(map[string]string{"/etc/vault.d/vault.hcl": vaultExample})
+
+		err := manipulator.DeleteValue("/etc/vault.d/vault.hcl", test.accessor)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate HCL file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/vault.d/vault.hcl"]
+
+		if output != test.expected {
+			t.Fatal("Deleting " + test.accessor + " must only remove the matching attribute or block (was: \"" + output + "\")")
+		}
+	}
+}
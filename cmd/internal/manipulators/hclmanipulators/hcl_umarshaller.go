@@ -0,0 +1,34 @@
+package hclmanipulators
+
+import (
+	"errors"
+)
+
+type HclUnmarshaller struct {
+}
+
+func (u HclUnmarshaller) UnmarshalMap(value string) (map[string]any, error) {
+	parsed, err := parseExpression([]byte(value))
+	if err != nil {
+		return nil, err
+	}
+
+	objectValue, ok := fromCty(parsed).(map[string]any)
+	if !ok {
+		return nil, errors.New("the value is not an object")
+	}
+	return objectValue, nil
+}
+
+func (u HclUnmarshaller) UnmarshalArray(value string) ([]any, error) {
+	parsed, err := parseExpression([]byte(value))
+	if err != nil {
+		return nil, err
+	}
+
+	arrayValue, ok := fromCty(parsed).([]any)
+	if !ok {
+		return nil, errors.New("the value is not an array")
+	}
+	return arrayValue, nil
+}
@@ -0,0 +1,109 @@
+package hclmanipulators
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"math/big"
+)
+
+// parseExpression evaluates an HCL expression that only contains literal values.
+func parseExpression(expression []byte) (cty.Value, error) {
+	parsed, diags := hclsyntax.ParseExpression(expression, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+
+	value, diags := parsed.Value(nil)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+
+	if !value.IsWhollyKnown() {
+		return cty.NilVal, errors.New("the expression does not have a known value")
+	}
+
+	return value, nil
+}
+
+// fromCty converts an HCL value to the generic values used by the MapManipulator. Whole numbers are returned as
+// int64 so they retain their type when they are replaced.
+func fromCty(value cty.Value) any {
+	if value.IsNull() {
+		return nil
+	}
+
+	valueType := value.Type()
+	switch {
+	case valueType == cty.String:
+		return value.AsString()
+	case valueType == cty.Bool:
+		return value.True()
+	case valueType == cty.Number:
+		number := value.AsBigFloat()
+		if integer, accuracy := number.Int64(); accuracy == big.Exact {
+			return integer
+		}
+		float, _ := number.Float64()
+		return float
+	case valueType.IsObjectType() || valueType.IsMapType():
+		result := map[string]any{}
+		for iterator := value.ElementIterator(); iterator.Next(); {
+			key, item := iterator.Element()
+			result[key.AsString()] = fromCty(item)
+		}
+		return result
+	case valueType.IsTupleType() || valueType.IsListType() || valueType.IsSetType():
+		result := []any{}
+		for iterator := value.ElementIterator(); iterator.Next(); {
+			_, item := iterator.Element()
+			result = append(result, fromCty(item))
+		}
+		return result
+	}
+
+	return nil
+}
+
+// toCty converts the generic values used by the MapManipulator to an HCL value.
+func toCty(value any) (cty.Value, error) {
+	switch typedValue := value.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	case string:
+		return cty.StringVal(typedValue), nil
+	case bool:
+		return cty.BoolVal(typedValue), nil
+	case int64:
+		return cty.NumberIntVal(typedValue), nil
+	case float64:
+		return cty.NumberFloatVal(typedValue), nil
+	case map[string]any:
+		attributes := map[string]cty.Value{}
+		for key, item := range typedValue {
+			converted, err := toCty(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			attributes[key] = converted
+		}
+		return cty.ObjectVal(attributes), nil
+	case []any:
+		if len(typedValue) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		items := []cty.Value{}
+		for _, item := range typedValue {
+			converted, err := toCty(item)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			items = append(items, converted)
+		}
+		return cty.TupleVal(items), nil
+	}
+
+	return cty.NilVal, errors.New("values of type " + fmt.Sprintf("%T", value) + " can not be written to HCL files")
+}
@@ -0,0 +1,341 @@
+package inimanipulators
+
+import (
+	"strings"
+)
+
+// iniEntry records the location of a section header or key in the original file.
+type iniEntry struct {
+	section string
+	// key is empty for section headers
+	key string
+	// firstLine and lastLine are the lines that make up the entry. Values wrapped in triple quotes may span
+	// many lines.
+	firstLine int
+	lastLine  int
+	// valueStart and valueEnd are the byte offsets of the value in the first and last lines
+	valueStart int
+	valueEnd   int
+	// delimiter is the text between the key and the value e.g. " = "
+	delimiter string
+	// quote is the character the original value was wrapped in, if any
+	quote string
+}
+
+// iniEditor edits the lines of an INI file in place, so comments, blank lines, section order, and the spacing
+// around keys and values are retained.
+type iniEditor struct {
+	lines   []string
+	entries []iniEntry
+	// lineEnding is "\r" if the file uses Windows line endings
+	lineEnding string
+}
+
+func newIniEditor(content string) *iniEditor {
+	editor := iniEditor{
+		lines: strings.Split(content, "\n"),
+	}
+
+	if strings.Contains(content, "\r\n") {
+		editor.lineEnding = "\r"
+	}
+
+	editor.parse()
+	return &editor
+}
+
+func (e *iniEditor) String() string {
+	return strings.Join(e.lines, "\n")
+}
+
+func (e *iniEditor) parse() {
+	e.entries = []iniEntry{}
+	section := ""
+
+	for i := 0; i < len(e.lines); i++ {
+		line := strings.TrimSuffix(e.lines[i], "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") && strings.Contains(trimmed, "]") {
+			section = e.normaliseSection(strings.TrimSpace(trimmed[1:strings.Index(trimmed, "]")]))
+			e.entries = append(e.entries, iniEntry{section: section, firstLine: i, lastLine: i})
+			continue
+		}
+
+		entry := iniEntry{section: section, firstLine: i, lastLine: i}
+
+		delimiter := strings.IndexAny(line, "=:")
+		if delimiter == -1 {
+			// Keys without values are treated as booleans
+			entry.key = trimmed
+			entry.valueStart = len(line)
+			entry.valueEnd = len(line)
+			e.entries = append(e.entries, entry)
+			continue
+		}
+
+		entry.key = strings.TrimSpace(line[:delimiter])
+		keyEnd := strings.Index(line, entry.key) + len(entry.key)
+		entry.valueStart = delimiter + 1
+		for entry.valueStart < len(line) && (line[entry.valueStart] == ' ' || line[entry.valueStart] == '\t') {
+			entry.valueStart++
+		}
+		entry.delimiter = line[keyEnd:entry.valueStart]
+
+		value := line[entry.valueStart:]
+		switch {
+		case strings.HasPrefix(value, "\"\"\""):
+			entry.quote = "\"\"\""
+			entry.lastLine, entry.valueEnd = e.findClosingQuote(i, entry.valueStart+3, "\"\"\"")
+		case strings.HasPrefix(value, "\"") || strings.HasPrefix(value, "'") || strings.HasPrefix(value, "`"):
+			entry.quote = value[:1]
+			entry.valueEnd = len(line)
+			if closing := strings.Index(value[1:], entry.quote); closing != -1 {
+				entry.valueEnd = entry.valueStart + closing + 2
+			}
+		default:
+			entry.valueEnd = entry.valueStart + e.valueLength(value)
+		}
+
+		e.entries = append(e.entries, entry)
+		i = entry.lastLine
+	}
+}
+
+// findClosingQuote returns the line and the offset after the quote that closes a multi-line value.
+func (e *iniEditor) findClosingQuote(line int, offset int, quote string) (int, int) {
+	for i := line; i < len(e.lines); i++ {
+		text := strings.TrimSuffix(e.lines[i], "\r")
+		start := 0
+		if i == line {
+			start = offset
+		}
+		if closing := strings.Index(text[start:], quote); closing != -1 {
+			return i, start + closing + len(quote)
+		}
+	}
+
+	last := len(e.lines) - 1
+	return last, len(strings.TrimSuffix(e.lines[last], "\r"))
+}
+
+// valueLength returns the length of an unquoted value, excluding any inline comment and trailing whitespace.
+func (e *iniEditor) valueLength(value string) int {
+	end := len(value)
+	for i := 1; i < len(value); i++ {
+		if (value[i] == '#' || value[i] == ';') && (value[i-1] == ' ' || value[i-1] == '\t') {
+			end = i
+			break
+		}
+	}
+
+	return len(strings.TrimRight(value[:end], " \t"))
+}
+
+// normaliseSection treats the DEFAULT section the same as keys that appear before any section header.
+func (e *iniEditor) normaliseSection(section string) string {
+	if section == "DEFAULT" {
+		return ""
+	}
+
+	return section
+}
+
+// SetValue replaces the value of every matching key, or adds the key to the end of the section if it does not
+// exist. New sections are added to the end of the file.
+func (e *iniEditor) SetValue(section string, key string, value string) {
+	section = e.normaliseSection(section)
+	found := false
+
+	// Entries are replaced from the end of the file so multi-line values do not shift the lines of earlier entries
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key != key || entry.section != section {
+			continue
+		}
+		found = true
+
+		first := e.lines[entry.firstLine]
+		last := e.lines[entry.lastLine]
+		delimiter := ""
+		if entry.delimiter == "" {
+			delimiter = e.newDelimiter(section)
+		}
+		replacement := first[:entry.valueStart] + delimiter + e.formatValue(value, entry.quote) + last[entry.valueEnd:]
+		e.lines = append(e.lines[:entry.firstLine], append([]string{replacement}, e.lines[entry.lastLine+1:]...)...)
+	}
+
+	if !found {
+		line := e.formatKey(section, key) + e.newDelimiter(section) + e.formatValue(value, "")
+
+		if insertAt, ok := e.endOfSection(section); ok {
+			e.insertLines(insertAt, []string{line})
+		} else {
+			insertAt = e.endOfFile()
+			newLines := []string{"[" + section + "]", line}
+
+			// Separate the new section from the existing content with a blank line
+			if insertAt > 0 && strings.TrimSpace(e.lines[insertAt-1]) != "" {
+				newLines = append([]string{""}, newLines...)
+			}
+			e.insertLines(insertAt, newLines)
+		}
+	}
+
+	e.parse()
+}
+
+// DeleteKey removes every matching key.
+func (e *iniEditor) DeleteKey(section string, key string) {
+	section = e.normaliseSection(section)
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key == key && entry.section == section {
+			e.deleteLines(entry.firstLine, entry.lastLine)
+		}
+	}
+
+	e.parse()
+}
+
+// DeleteSection removes the section header, the keys in the section, and any blank lines that follow them.
+// Comments before the next section header are retained.
+func (e *iniEditor) DeleteSection(section string) {
+	section = e.normaliseSection(section)
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key != "" || entry.section != section {
+			continue
+		}
+
+		end := entry.lastLine
+		for _, next := range e.entries[i+1:] {
+			if next.key == "" {
+				break
+			}
+			end = next.lastLine
+		}
+
+		for end+1 < e.endOfFile() && strings.TrimSpace(e.lines[end+1]) == "" {
+			end++
+		}
+
+		e.deleteLines(entry.firstLine, end)
+	}
+
+	e.parse()
+}
+
+// endOfSection returns the line after the last key in the section. The default section always exists, and new keys
+// are added before the first section header.
+func (e *iniEditor) endOfSection(section string) (int, bool) {
+	insertAt := -1
+	for _, entry := range e.entries {
+		if entry.section == section {
+			insertAt = entry.lastLine + 1
+		}
+	}
+
+	if insertAt != -1 {
+		return insertAt, true
+	}
+
+	if section == "" {
+		for _, entry := range e.entries {
+			if entry.key == "" {
+				return entry.firstLine, true
+			}
+		}
+
+		return e.endOfFile(), true
+	}
+
+	return 0, false
+}
+
+// endOfFile returns the index new lines are inserted at to add them to the end of the file.
+func (e *iniEditor) endOfFile() int {
+	if e.lines[len(e.lines)-1] == "" {
+		return len(e.lines) - 1
+	}
+
+	return len(e.lines)
+}
+
+// insertLines inserts new lines before the line at the supplied index.
+func (e *iniEditor) insertLines(index int, newLines []string) {
+	withEndings := []string{}
+	for _, line := range newLines {
+		withEndings = append(withEndings, line+e.lineEnding)
+	}
+
+	if index == len(e.lines) {
+		// The file does not end with a line break, so the last line is terminated and the new lines are not
+		e.lines[index-1] += e.lineEnding
+		withEndings[len(withEndings)-1] = newLines[len(newLines)-1]
+	}
+
+	e.lines = append(e.lines[:index], append(withEndings, e.lines[index:]...)...)
+}
+
+// deleteLines removes the lines from first to last inclusive.
+func (e *iniEditor) deleteLines(first int, last int) {
+	e.lines = append(e.lines[:first], e.lines[last+1:]...)
+}
+
+// formatKey uses the indentation of the existing keys in the section.
+func (e *iniEditor) formatKey(section string, key string) string {
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key != "" && entry.section == section {
+			line := e.lines[entry.firstLine]
+			return line[:len(line)-len(strings.TrimLeft(line, " \t"))] + key
+		}
+	}
+
+	return key
+}
+
+// newDelimiter uses the same spacing around the delimiter as the last key in the section, or the first key in the
+// file if the section has no keys.
+func (e *iniEditor) newDelimiter(section string) string {
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		if e.entries[i].delimiter != "" && e.entries[i].section == section {
+			return e.entries[i].delimiter
+		}
+	}
+
+	for _, entry := range e.entries {
+		if entry.delimiter != "" {
+			return entry.delimiter
+		}
+	}
+
+	return " = "
+}
+
+// formatValue quotes values in the same way as ini.File.WriteTo, retaining the original quotes if the value
+// does not require a different style.
+func (e *iniEditor) formatValue(value string, quote string) string {
+	if strings.ContainsAny(value, "\n`") {
+		return "\"\"\"" + value + "\"\"\""
+	}
+
+	if strings.ContainsAny(value, "#;") {
+		return "`" + value + "`"
+	}
+
+	if len(strings.TrimSpace(value)) != len(value) {
+		return "\"" + value + "\""
+	}
+
+	if quote != "" && !strings.Contains(value, quote) {
+		return quote + value + quote
+	}
+
+	return value
+}
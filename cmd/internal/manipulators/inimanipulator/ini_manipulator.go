@@ -2,12 +2,15 @@ package inimanipulators
 
 import (
 	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"gopkg.in/ini.v1"
 	"strings"
 )
 
+// IniManipulator edits INI files line by line, so comments, section order, and the spacing around each key are
+// retained.
 type IniManipulator struct {
 	Writer writers.Writer
 	Reader readers.Reader
@@ -18,13 +21,34 @@ func (m IniManipulator) GetFormatName() string {
 }
 
 func (m IniManipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m IniManipulator) MatchesFileName(fileSpec string) bool {
+	return strings.HasSuffix(fileSpec, ".ini")
+}
+
+func (m IniManipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m IniManipulator) ParseError(fileSpec string) error {
 	content, err := m.Reader.ReadString(fileSpec)
 	if err != nil {
-		return false
+		return err
 	}
 
 	_, err = ini.Load([]byte(content))
-	return err == nil && strings.HasSuffix(fileSpec, ".ini")
+	return err
+}
+
+func (m IniManipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
+	if err := manipulators.ValidateTextValue(m.GetFormatName(), value, valueType); err != nil {
+		return err
+	}
+
+	return m.SetValue(fileSpec, valueSpec, value)
 }
 
 func (m IniManipulator) SetValue(fileSpec string, valueSpec string, value string) error {
@@ -38,19 +62,21 @@ func (m IniManipulator) SetValue(fileSpec string, valueSpec string, value string
 		return err
 	}
 
-	path := strings.Split(valueSpec, ":")
+	section, key, err := m.getSectionAndKey(valueSpec)
+	if err != nil {
+		return err
+	}
 
-	if !(len(path) == 1 || len(path) == 2) {
-		return errors.New("path must be a single key or section and key separated by a colon")
+	if key == "" {
+		return errors.New("path must include a key to set")
 	}
 
-	section := ""
-	key := ""
-	if len(path) == 2 {
-		section = path[0]
-		key = path[1]
-	} else {
-		key = path[0]
+	// Edit the original lines, falling back to writing the parsed file if the edited file does not contain the
+	// new value
+	editor := newIniEditor(content)
+	editor.SetValue(section, key, value)
+	if edited, err := ini.Load([]byte(editor.String())); err == nil && edited.Section(section).Key(key).String() == value {
+		return m.Writer.WriteString(fileSpec, editor.String())
 	}
 
 	result.Section(section).Key(key).SetValue(value)
@@ -60,3 +86,67 @@ func (m IniManipulator) SetValue(fileSpec string, valueSpec string, value string
 
 	return m.Writer.WriteString(fileSpec, stringWriter.Output)
 }
+
+// DeleteValue removes a key from a section, or removes an entire section if the path is a section name followed by
+// a colon e.g. "group:".
+func (m IniManipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	result, err := ini.Load([]byte(content))
+	if err != nil {
+		return err
+	}
+
+	section, key, err := m.getSectionAndKey(valueSpec)
+	if err != nil {
+		return err
+	}
+
+	editor := newIniEditor(content)
+	if key == "" {
+		editor.DeleteSection(section)
+	} else {
+		editor.DeleteKey(section, key)
+	}
+	if edited, err := ini.Load([]byte(editor.String())); err == nil && !m.hasValue(edited, section, key) {
+		return m.Writer.WriteString(fileSpec, editor.String())
+	}
+
+	if key == "" {
+		result.DeleteSection(section)
+	} else if existingSection, err := result.GetSection(section); err == nil {
+		existingSection.DeleteKey(key)
+	}
+
+	stringWriter := writers.StringIOWriter{}
+	_, err = result.WriteTo(&stringWriter)
+
+	return m.Writer.WriteString(fileSpec, stringWriter.Output)
+}
+
+// hasValue returns true if the file contains the key, or the section if the key is empty.
+func (m IniManipulator) hasValue(file *ini.File, section string, key string) bool {
+	existingSection, err := file.GetSection(section)
+	if err != nil {
+		return false
+	}
+
+	return key == "" || existingSection.HasKey(key)
+}
+
+func (m IniManipulator) getSectionAndKey(valueSpec string) (string, string, error) {
+	path := manipulators.SplitPath(valueSpec)
+
+	if !(len(path) == 1 || len(path) == 2) {
+		return "", "", errors.New("path must be a single key or section and key separated by a colon")
+	}
+
+	if len(path) == 2 {
+		return manipulators.PathKey(path[0]), manipulators.PathKey(path[1]), nil
+	}
+
+	return "", manipulators.PathKey(path[0]), nil
+}
@@ -1,9 +1,11 @@
 package inimanipulators
 
 import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"gopkg.in/ini.v1"
+	"strings"
 	"testing"
 )
 
@@ -226,3 +228,222 @@ func TestSetIniBoolField(t *testing.T) {
 		t.Fatal("Value must be set to \"true\" (was: \"" + value + "\"")
 	}
 }
+
+func TestDeleteIniGroupField(t *testing.T) {
+	iniExample := "whatever = value\n[group]\nwhatever = value\nother = value"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.ini": iniExample,
+		},
+	}
+	manipulator := IniManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.DeleteValue("/etc/config.ini", "group:whatever")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate INI file: " + err.Error())
+	}
+
+	result, err := ini.Load([]byte((*writer.Output)["/etc/config.ini"]))
+
+	if result.Section("group").HasKey("whatever") {
+		t.Fatal("Key must have been deleted")
+	}
+
+	if !result.Section("group").HasKey("other") || !result.Section("").HasKey("whatever") {
+		t.Fatal("Other keys must be retained")
+	}
+}
+
+func TestDeleteIniSection(t *testing.T) {
+	iniExample := "whatever = value\n[group]\nwhatever = value\n[other]\nwhatever = value"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.ini": iniExample,
+		},
+	}
+	manipulator := IniManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.DeleteValue("/etc/config.ini", "group:")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate INI file: " + err.Error())
+	}
+
+	result, err := ini.Load([]byte((*writer.Output)["/etc/config.ini"]))
+
+	if result.HasSection("group") {
+		t.Fatal("Section must have been deleted")
+	}
+
+	if !result.HasSection("other") {
+		t.Fatal("Other sections must be retained")
+	}
+}
+
+func TestDeleteIniMissingField(t *testing.T) {
+	iniExample := "whatever = value"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.ini": iniExample,
+		},
+	}
+	manipulator := IniManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.DeleteValue("/etc/config.ini", "missing:whatever")
+
+	if err != nil {
+		t.Fatal("Deleting a missing value must not fail: " + err.Error())
+	}
+}
+
+const commentedIni = `; Global settings
+name=app
+
+# Database settings
+[database]
+host     = localhost   ; the database host
+port     = 5432
+password = "secret"
+
+[logging]
+level: info
+`
+
+func TestSetIniPreservesFormatting(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.ini": commentedIni,
+		},
+	}
+	manipulator := IniManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"name", "other", strings.Replace(commentedIni, "name=app", "name=other", 1)},
+		{"database:host", "db.example.org", strings.Replace(commentedIni, "localhost   ;", "db.example.org   ;", 1)},
+		{"database:password", "changed", strings.Replace(commentedIni, "\"secret\"", "\"changed\"", 1)},
+		{"logging:level", "debug", strings.Replace(commentedIni, "level: info", "level: debug", 1)},
+		{"timeout", "30", strings.Replace(commentedIni, "name=app\n", "name=app\ntimeout=30\n", 1)},
+		{"database:user", "admin", strings.Replace(commentedIni, "password = \"secret\"\n", "password = \"secret\"\nuser = admin\n", 1)},
+		{"cache:size", "10", commentedIni + "\n[cache]\nsize=10\n"},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/config.ini", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate INI file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.ini"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change one line (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestDeleteIniPreservesFormatting(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.ini": commentedIni,
+		},
+	}
+	manipulator := IniManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	tests := []struct {
+		accessor string
+		expected string
+	}{
+		{"database:port", strings.Replace(commentedIni, "port     = 5432\n", "", 1)},
+		{"database:", strings.Replace(commentedIni, "[database]\nhost     = localhost   ; the database host\nport     = 5432\npassword = \"secret\"\n\n", "", 1)},
+		{"logging:", strings.Replace(commentedIni, "[logging]\nlevel: info\n", "", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.DeleteValue("/etc/config.ini", test.accessor)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate INI file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.ini"]
+
+		if output != test.expected {
+			t.Fatal("Deleting " + test.accessor + " must only remove its lines (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestSetIniTypedValue(t *testing.T) {
+	tests := []struct {
+		value     string
+		valueType manipulators.ValueType
+		valid     bool
+	}{
+		{"8080", manipulators.ValueTypeInt, true},
+		{"abc", manipulators.ValueTypeInt, false},
+		{"1.5", manipulators.ValueTypeFloat, true},
+		{"abc", manipulators.ValueTypeFloat, false},
+		{"true", manipulators.ValueTypeBool, true},
+		{"abc", manipulators.ValueTypeBool, false},
+		{"abc", manipulators.ValueTypeString, true},
+		{"{}", manipulators.ValueTypeObject, false},
+		{"", manipulators.ValueTypeNull, false},
+	}
+
+	for _, test := range tests {
+		writer := writers.StringWriter{}
+		reader := readers.StringReader{
+			Files: &map[string]string{
+				"/etc/config.ini": "port = 80",
+			},
+		}
+		manipulator := IniManipulator{
+			Writer: &writer,
+			Reader: reader,
+		}
+
+		err := manipulator.SetTypedValue("/etc/config.ini", "port", test.value, test.valueType)
+
+		if test.valid && err != nil {
+			t.Fatal("The value \"" + test.value + "\" must be valid for the " + test.valueType.String() + " hint: " + err.Error())
+		}
+
+		if !test.valid && err == nil {
+			t.Fatal("The value \"" + test.value + "\" must be rejected for the " + test.valueType.String() + " hint")
+		}
+
+		if test.valid && !strings.Contains((*writer.Output)["/etc/config.ini"], "port = "+test.value) {
+			t.Fatal("The value must be written as text (was \"" + (*writer.Output)["/etc/config.ini"] + "\")")
+		}
+
+		if !test.valid && writer.Output != nil {
+			t.Fatal("The file must not be written when the value does not match the hint")
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package jsonmanipulators
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// json5DecimalPattern matches JSON5 decimal numbers without a sign, which may start or end with a decimal point.
+var json5DecimalPattern = regexp.MustCompile("^([0-9]+)?(?:\\.([0-9]*))?([eE][+-]?[0-9]+)?$")
+
+// json5HexPattern matches JSON5 hexadecimal numbers without a sign.
+var json5HexPattern = regexp.MustCompile("^0[xX][0-9a-fA-F]+$")
+
+// unmarshalJson5 parses a JSON5 document. Numbers are decoded as json.Number, in the same way as unmarshal.
+func unmarshalJson5(value string) (any, error) {
+	parser := jsonParser{content: value, json5: true}
+	root, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJson5(value, root)
+}
+
+// decodeJson5 returns the value of a node parsed from a JSON5 document.
+func decodeJson5(content string, node *jsonNode) (any, error) {
+	switch node.kind {
+	case '{':
+		result := map[string]any{}
+		for _, child := range node.children {
+			value, err := decodeJson5(content, child.value)
+			if err != nil {
+				return nil, err
+			}
+			result[child.key] = value
+		}
+		return result, nil
+	case '[':
+		result := []any{}
+		for _, child := range node.children {
+			value, err := decodeJson5(content, child.value)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	}
+
+	text := content[node.start:node.end]
+	if text[0] == '"' || text[0] == '\'' {
+		return unquoteJson5(text)
+	}
+
+	return decodeJson5Literal(text)
+}
+
+// decodeJson5Literal decodes booleans, nulls, and numbers. Numbers are converted to the equivalent JSON number where
+// possible, so hexadecimal numbers like "0xFF" are decoded as "255". Infinity and NaN have no JSON equivalent, and
+// are retained as is.
+func decodeJson5Literal(text string) (any, error) {
+	switch text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	sign := ""
+	number := text
+	if strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+") {
+		if number[0] == '-' {
+			sign = "-"
+		}
+		number = number[1:]
+	}
+
+	if number == "Infinity" || number == "NaN" {
+		return json.Number(sign + number), nil
+	}
+
+	if json5HexPattern.MatchString(number) {
+		integer, err := strconv.ParseUint(number[2:], 16, 64)
+		if err != nil {
+			return nil, errors.New("the number " + text + " is too large")
+		}
+		return json.Number(sign + strconv.FormatUint(integer, 10)), nil
+	}
+
+	match := json5DecimalPattern.FindStringSubmatch(number)
+	if match == nil || match[1] == "" && match[2] == "" {
+		return nil, errors.New("unexpected value " + text)
+	}
+
+	// Leading zeros and decimal points without digits on both sides are not valid in JSON
+	integer := strings.TrimLeft(match[1], "0")
+	if integer == "" {
+		integer = "0"
+	}
+
+	fraction := ""
+	if match[2] != "" {
+		fraction = "." + match[2]
+	}
+
+	return json.Number(sign + integer + fraction + match[3]), nil
+}
+
+// unquoteJson5 decodes a single or double quoted JSON5 string, including the quotes.
+func unquoteJson5(text string) (string, error) {
+	body := text[1 : len(text)-1]
+	var builder strings.Builder
+
+	for i := 0; i < len(body); i++ {
+		if body[i] == '\n' || body[i] == '\r' {
+			return "", errors.New("strings must not contain unescaped line breaks")
+		}
+
+		if body[i] != '\\' || i+1 >= len(body) {
+			builder.WriteByte(body[i])
+			continue
+		}
+
+		i++
+		switch body[i] {
+		case 'b':
+			builder.WriteByte('\b')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 't':
+			builder.WriteByte('\t')
+		case 'v':
+			builder.WriteByte('\v')
+		case '0':
+			builder.WriteByte(0)
+		case 'x':
+			if i+3 > len(body) {
+				return "", errors.New("malformed \\x escape in string")
+			}
+			character, err := strconv.ParseUint(body[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", errors.New("malformed \\x escape in string")
+			}
+			builder.WriteRune(rune(character))
+			i += 2
+		case 'u':
+			if i+5 > len(body) {
+				return "", errors.New("malformed \\u escape in string")
+			}
+			unit, err := strconv.ParseUint(body[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", errors.New("malformed \\u escape in string")
+			}
+			i += 4
+
+			// Characters outside of the basic multilingual plane are written as a pair of escapes
+			character := rune(unit)
+			if utf16.IsSurrogate(character) && i+7 <= len(body) && body[i+1:i+3] == "\\u" {
+				if low, err := strconv.ParseUint(body[i+3:i+7], 16, 16); err == nil {
+					if decoded := utf16.DecodeRune(character, rune(low)); decoded != unicode.ReplacementChar {
+						character = decoded
+						i += 6
+					}
+				}
+			}
+			builder.WriteRune(character)
+		case '\r':
+			// A line continuation, which is removed from the string
+			if i+1 < len(body) && body[i+1] == '\n' {
+				i++
+			}
+		case '\n':
+		default:
+			builder.WriteByte(body[i])
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// isIdentifierCharacter returns true if the character can be used in an unquoted JSON5 key.
+func isIdentifierCharacter(c byte, first bool) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || !first && c >= '0' && c <= '9'
+}
@@ -0,0 +1,136 @@
+package jsonmanipulators
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+)
+
+// json5Extensions are the file extensions used by JSON documents that may contain comments and trailing commas.
+var json5Extensions = []string{".json", ".jsonc", ".json5"}
+
+// Json5Manipulator edits JSON5 files, along with files like tsconfig.json and VS Code settings that use JSON with
+// comments and trailing commas. Comments, trailing commas, unquoted keys, and the formatting of unchanged values are
+// retained. Plain JSON files are edited by the JsonManipulator, so it must be tried before this manipulator.
+type Json5Manipulator struct {
+	Writer         writers.Writer
+	Reader         readers.Reader
+	MapManipulator manipulators.MapManipulator
+}
+
+func (m Json5Manipulator) GetFormatName() string {
+	return "JSON5"
+}
+
+func (m Json5Manipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m Json5Manipulator) MatchesFileName(fileSpec string) bool {
+	return m.hasJson5Extension(fileSpec)
+}
+
+func (m Json5Manipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m Json5Manipulator) ParseError(fileSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.unmarshal(content)
+	return err
+}
+
+func (m Json5Manipulator) SetValue(fileSpec string, valueSpec string, value string) error {
+	return m.SetTypedValue(fileSpec, valueSpec, value, manipulators.ValueTypeAuto)
+}
+
+func (m Json5Manipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	result, err := m.unmarshal(content)
+	if err != nil {
+		return err
+	}
+
+	result, err = m.MapManipulator.ProcessMap(result, valueSpec, value, valueType)
+	if err != nil {
+		return err
+	}
+
+	json, err := m.render(content, result)
+	if err != nil {
+		return err
+	}
+	err = m.Writer.WriteString(fileSpec, json)
+	return err
+}
+
+func (m Json5Manipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	result, err := m.unmarshal(content)
+	if err != nil {
+		return err
+	}
+
+	result, err = m.MapManipulator.DeleteFromMap(result, valueSpec)
+	if err != nil {
+		return err
+	}
+
+	json, err := m.render(content, result)
+	if err != nil {
+		return err
+	}
+	err = m.Writer.WriteString(fileSpec, json)
+	return err
+}
+
+// unmarshal parses the document, which must have an object as the root value.
+func (m Json5Manipulator) unmarshal(content string) (map[string]any, error) {
+	value, err := unmarshalJson5(content)
+	if err != nil {
+		return nil, err
+	}
+
+	result, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("the root of the document must be an object")
+	}
+
+	return result, nil
+}
+
+// render writes the modified object back into the original content, so only new or changed values are reformatted.
+func (m Json5Manipulator) render(content string, result map[string]any) (string, error) {
+	parser := jsonParser{content: content, json5: true}
+	root, err := parser.parse()
+	if err != nil {
+		return "", err
+	}
+
+	return newJsonRenderer(content, true).renderDocument(root, result)
+}
+
+func (m Json5Manipulator) hasJson5Extension(fileSpec string) bool {
+	for _, extension := range json5Extensions {
+		if strings.HasSuffix(fileSpec, extension) {
+			return true
+		}
+	}
+
+	return false
+}
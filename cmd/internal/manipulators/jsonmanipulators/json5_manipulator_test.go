@@ -0,0 +1,220 @@
+package jsonmanipulators
+
+import (
+	"fmt"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+	"testing"
+)
+
+const tsconfigExample = "{\n" +
+	"  // Compiler settings\n" +
+	"  \"compilerOptions\": {\n" +
+	"    \"target\": \"es2017\", // the output language\n" +
+	"    \"strict\": true,\n" +
+	"    /* paths are relative to baseUrl */\n" +
+	"    \"baseUrl\": \".\",\n" +
+	"  },\n" +
+	"  \"include\": [\"src\", \"types\",],\n" +
+	"}\n"
+
+const json5Example = "// Server settings\n" +
+	"{\n" +
+	"  name: 'web',\n" +
+	"  port: 0x1F90,\n" +
+	"  ratio: .5,\n" +
+	"  limit: +Infinity,\n" +
+	"  greeting: 'Hello \\\n" +
+	"world',\n" +
+	"  hosts: ['a', 'b'], // the upstream hosts\n" +
+	"}\n"
+
+func newJson5Manipulator(files map[string]string) (Json5Manipulator, *writers.StringWriter) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &files,
+	}
+	manipulator := Json5Manipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: Json5Unmarshaller{},
+		},
+	}
+
+	return manipulator, &writer
+}
+
+func TestJson5FileNames(t *testing.T) {
+	manipulator, _ := newJson5Manipulator(map[string]string{
+		"/app/tsconfig.json":             tsconfigExample,
+		"/app/.vscode/settings.jsonc":    "{\"editor.tabSize\": 2, /* spaces */}",
+		"/app/config.json5":              json5Example,
+		"/app/config.yaml":               "{a: 1}",
+		"/app/array.json5":               "[1, 2]",
+		"/app/unterminated.jsonc":        "{/* comment}",
+		"/app/invalid.json5":             "{a: 1 b: 2}",
+		"/app/invalid-number.json5":      "{a: 1.2.3}",
+		"/app/invalid-string.json5":      "{a: 'line\nbreak'}",
+		"/app/trailing-content.json5":    "{a: 1} {}",
+		"/app/missing-value.jsonc":       "{\"a\": }",
+		"/app/double-trailing.jsonc":     "{\"a\": 1,,}",
+		"/app/leading-comma.jsonc":       "{,}",
+		"/app/invalid-identifier.jsonc":  "{1a: 1}",
+		"/app/invalid-hex-escape.jsonc":  "{a: '\\xZZ'}",
+		"/app/invalid-unicode-esc.jsonc": "{a: '\\u12'}",
+	})
+
+	for _, file := range []string{"/app/tsconfig.json", "/app/.vscode/settings.jsonc", "/app/config.json5"} {
+		if !manipulator.CanManipulate(file) {
+			t.Fatal("Must be able to manipulate " + file)
+		}
+	}
+
+	for _, file := range []string{"/app/config.yaml", "/app/array.json5", "/app/unterminated.jsonc", "/app/invalid.json5",
+		"/app/invalid-number.json5", "/app/invalid-string.json5", "/app/trailing-content.json5", "/app/missing-value.jsonc",
+		"/app/double-trailing.jsonc", "/app/leading-comma.jsonc", "/app/invalid-identifier.jsonc",
+		"/app/invalid-hex-escape.jsonc", "/app/invalid-unicode-esc.jsonc"} {
+		if manipulator.CanManipulate(file) {
+			t.Fatal("Must not be able to manipulate " + file)
+		}
+	}
+}
+
+func TestJson5Values(t *testing.T) {
+	value, err := unmarshalJson5(json5Example)
+
+	if err != nil {
+		t.Fatal("Failed to parse JSON5: " + err.Error())
+	}
+
+	object := value.(map[string]any)
+	expected := map[string]string{
+		"name":     "web",
+		"port":     "8080",
+		"ratio":    "0.5",
+		"limit":    "Infinity",
+		"greeting": "Hello world",
+	}
+
+	for key, expectedValue := range expected {
+		if actual, ok := object[key]; !ok || fmt.Sprint(actual) != expectedValue {
+			t.Fatal("The key " + key + " must have the value " + expectedValue)
+		}
+	}
+
+	value, err = unmarshalJson5("'\\u0041\\x42\\'\\ud83d\\ude00'")
+
+	if err != nil || value != "AB'😀" {
+		t.Fatal("Escape sequences must be decoded")
+	}
+}
+
+func TestJson5SetPreservesComments(t *testing.T) {
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"compilerOptions:target", "es2020", strings.Replace(tsconfigExample, "\"es2017\"", "\"es2020\"", 1)},
+		{"compilerOptions:strict", "false", strings.Replace(tsconfigExample, "\"strict\": true", "\"strict\": false", 1)},
+		{"include:1", "lib", strings.Replace(tsconfigExample, "\"types\"", "\"lib\"", 1)},
+		{"include:-", "lib", strings.Replace(tsconfigExample, "\"types\",]", "\"types\", \"lib\",]", 1)},
+		{"compilerOptions:outDir", "dist", strings.Replace(tsconfigExample, "\"baseUrl\": \".\",\n", "\"baseUrl\": \".\",\n    \"outDir\": \"dist\",\n", 1)},
+	}
+
+	for _, test := range tests {
+		manipulator, writer := newJson5Manipulator(map[string]string{"/app/tsconfig.json": tsconfigExample})
+
+		err := manipulator.SetValue("/app/tsconfig.json", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON5 file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/app/tsconfig.json"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the matching value (was: \"" + output + "\")")
+		}
+	}
+}
+
+func TestJson5SetRetainsJson5Syntax(t *testing.T) {
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"port", "9090", strings.Replace(json5Example, "0x1F90", "9090", 1)},
+		{"ratio", "0.75", strings.Replace(json5Example, ".5", "0.75", 1)},
+		{"name", "api", strings.Replace(json5Example, "'web'", "\"api\"", 1)},
+		{"hosts:0", "c", strings.Replace(json5Example, "['a', 'b']", "[\"c\", 'b']", 1)},
+		{"timeout", "30", strings.Replace(json5Example, "// the upstream hosts\n", "// the upstream hosts\n  timeout: \"30\",\n", 1)},
+		{"\"content-type\"", "json", strings.Replace(json5Example, "// the upstream hosts\n", "// the upstream hosts\n  \"content-type\": \"json\",\n", 1)},
+	}
+
+	for _, test := range tests {
+		manipulator, writer := newJson5Manipulator(map[string]string{"/app/config.json5": json5Example})
+
+		err := manipulator.SetValue("/app/config.json5", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON5 file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/app/config.json5"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the matching value (was: \"" + output + "\")")
+		}
+	}
+}
+
+func TestJson5SetAfterLineComment(t *testing.T) {
+	jsonExample := "{\n  \"a\": 1 // the first value\n}"
+	manipulator, writer := newJson5Manipulator(map[string]string{"/app/config.jsonc": jsonExample})
+
+	err := manipulator.SetTypedValue("/app/config.jsonc", "b", "{c: [1, 2]}", manipulators.ValueTypeObject)
+
+	if err != nil {
+		t.Fatal("Failed to manipulate JSON5 file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/app/config.jsonc"]
+	expected := "{\n  \"a\": 1, // the first value\n  \"b\": {\n    \"c\": [\n      1,\n      2\n    ]\n  }\n}"
+
+	if output != expected {
+		t.Fatal("The comment must remain on the line of the value it describes (was: \"" + output + "\")")
+	}
+}
+
+func TestJson5DeletePreservesComments(t *testing.T) {
+	tests := []struct {
+		accessor string
+		expected string
+	}{
+		{"compilerOptions:baseUrl", strings.Replace(tsconfigExample, "    /* paths are relative to baseUrl */\n    \"baseUrl\": \".\",\n", "", 1)},
+		{"compilerOptions:target", strings.Replace(tsconfigExample, "\"target\": \"es2017\", // the output language\n    ", "", 1)},
+		{"include:0", strings.Replace(tsconfigExample, "\"src\", ", "", 1)},
+		{"missing", tsconfigExample},
+	}
+
+	for _, test := range tests {
+		manipulator, writer := newJson5Manipulator(map[string]string{"/app/tsconfig.json": tsconfigExample})
+
+		err := manipulator.DeleteValue("/app/tsconfig.json", test.accessor)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON5 file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/app/tsconfig.json"]
+
+		if output != test.expected {
+			t.Fatal("Deleting " + test.accessor + " must only remove the matching value (was: \"" + output + "\")")
+		}
+	}
+}
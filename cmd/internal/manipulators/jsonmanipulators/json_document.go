@@ -0,0 +1,239 @@
+package jsonmanipulators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// jsonNode records the location of a value in the original document, along with the whitespace surrounding the
+// members of objects and the items of arrays. This allows a modified document to be written with the same key order
+// and formatting as the original.
+type jsonNode struct {
+	start int
+	end   int
+	// kind is '{' for objects, '[' for arrays, and 0 for any other value
+	kind     byte
+	children []jsonChild
+	// closing is the text between the last child and the closing bracket
+	closing string
+	// trailingComma is true if the last child is followed by a comma, which is allowed by JSON5
+	trailingComma bool
+}
+
+// jsonChild is an object member or an array item.
+type jsonChild struct {
+	// before is the text between the preceding bracket or comma and the key or value
+	before string
+	key    string
+	// keyText is the original text of the key, including quotes
+	keyText string
+	// colon is the text between the key and the value, including the colon
+	colon string
+	value *jsonNode
+	// after is the text between the value and the following comma
+	after string
+}
+
+// jsonParser scans a document that is known to be valid JSON, recording the location of each value. When json5 is
+// true, the parser validates the document as JSON5, which adds comments, trailing commas, unquoted keys, single
+// quoted strings, and additional number formats to JSON.
+type jsonParser struct {
+	content string
+	pos     int
+	json5   bool
+}
+
+func (p *jsonParser) parse() (*jsonNode, error) {
+	p.skipWhitespace()
+	root, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.json5 {
+		p.skipWhitespace()
+		if p.pos < len(p.content) {
+			return nil, errors.New("unexpected character " + fmt.Sprintf("%q", p.content[p.pos]) + " at offset " + fmt.Sprint(p.pos))
+		}
+	}
+
+	return root, nil
+}
+
+func (p *jsonParser) parseValue() (*jsonNode, error) {
+	if p.pos >= len(p.content) {
+		return nil, errors.New("unexpected end of JSON document")
+	}
+
+	switch p.content[p.pos] {
+	case '{', '[':
+		return p.parseContainer()
+	case '"', '\'':
+		start := p.pos
+		_, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{start: start, end: p.pos}, nil
+	default:
+		start := p.pos
+		for p.pos < len(p.content) && !p.isDelimiter(p.content[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			return nil, errors.New("unexpected character " + fmt.Sprintf("%q", p.content[p.pos]) + " at offset " + fmt.Sprint(p.pos))
+		}
+		if p.json5 {
+			if _, err := decodeJson5Literal(p.content[start:p.pos]); err != nil {
+				return nil, errors.New(err.Error() + " at offset " + fmt.Sprint(start))
+			}
+		}
+		return &jsonNode{start: start, end: p.pos}, nil
+	}
+}
+
+func (p *jsonParser) parseContainer() (*jsonNode, error) {
+	node := &jsonNode{start: p.pos, kind: p.content[p.pos]}
+	closeBracket := byte(']')
+	if node.kind == '{' {
+		closeBracket = '}'
+	}
+	p.pos++
+
+	for {
+		before := p.skipWhitespace()
+		if p.pos >= len(p.content) {
+			return nil, errors.New("unexpected end of JSON document")
+		}
+
+		if p.content[p.pos] == closeBracket && (len(node.children) == 0 || p.json5) {
+			node.closing = before
+			node.trailingComma = len(node.children) != 0
+			break
+		}
+
+		child := jsonChild{before: before}
+
+		if node.kind == '{' {
+			keyStart := p.pos
+			key, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			child.key = key
+			child.keyText = p.content[keyStart:p.pos]
+
+			colonStart := p.pos
+			p.skipWhitespace()
+			if p.pos >= len(p.content) || p.content[p.pos] != ':' {
+				return nil, errors.New("expected a colon at offset " + fmt.Sprint(p.pos))
+			}
+			p.pos++
+			p.skipWhitespace()
+			child.colon = p.content[colonStart:p.pos]
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		child.value = value
+
+		after := p.skipWhitespace()
+		if p.pos >= len(p.content) {
+			return nil, errors.New("unexpected end of JSON document")
+		}
+
+		if p.content[p.pos] == ',' {
+			child.after = after
+			node.children = append(node.children, child)
+			p.pos++
+		} else if p.content[p.pos] == closeBracket {
+			node.children = append(node.children, child)
+			node.closing = after
+			break
+		} else {
+			return nil, errors.New("unexpected character " + fmt.Sprintf("%q", p.content[p.pos]) + " at offset " + fmt.Sprint(p.pos))
+		}
+	}
+
+	p.pos++
+	node.end = p.pos
+	return node, nil
+}
+
+// parseKey returns the decoded key at the current position. JSON5 keys may be identifiers without quotes.
+func (p *jsonParser) parseKey() (string, error) {
+	if !p.json5 || p.pos >= len(p.content) || p.content[p.pos] == '"' || p.content[p.pos] == '\'' {
+		return p.parseString()
+	}
+
+	start := p.pos
+	for p.pos < len(p.content) && isIdentifierCharacter(p.content[p.pos], p.pos == start) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return "", errors.New("expected a key at offset " + fmt.Sprint(p.pos))
+	}
+
+	return p.content[start:p.pos], nil
+}
+
+// parseString returns the decoded value of the string at the current position.
+func (p *jsonParser) parseString() (string, error) {
+	start := p.pos
+	if p.pos >= len(p.content) || !(p.content[p.pos] == '"' || p.json5 && p.content[p.pos] == '\'') {
+		return "", errors.New("expected a string at offset " + fmt.Sprint(p.pos))
+	}
+
+	quote := p.content[p.pos]
+	for p.pos++; p.pos < len(p.content); p.pos++ {
+		if p.content[p.pos] == '\\' {
+			p.pos++
+		} else if p.content[p.pos] == quote {
+			p.pos++
+			if p.json5 {
+				return unquoteJson5(p.content[start:p.pos])
+			}
+			return unquote(p.content[start:p.pos])
+		}
+	}
+
+	return "", errors.New("unterminated string at offset " + fmt.Sprint(start))
+}
+
+// skipWhitespace moves past any whitespace, and any comments in JSON5 documents, returning the text that was skipped.
+func (p *jsonParser) skipWhitespace() string {
+	start := p.pos
+	for p.pos < len(p.content) {
+		if p.isWhitespace(p.content[p.pos]) {
+			p.pos++
+		} else if p.json5 && strings.HasPrefix(p.content[p.pos:], "//") {
+			if index := strings.Index(p.content[p.pos:], "\n"); index != -1 {
+				p.pos += index
+			} else {
+				p.pos = len(p.content)
+			}
+		} else if p.json5 && strings.HasPrefix(p.content[p.pos:], "/*") {
+			index := strings.Index(p.content[p.pos+2:], "*/")
+			if index == -1 {
+				// The unterminated comment is reported as an unexpected character
+				break
+			}
+			p.pos += index + 4
+		} else {
+			break
+		}
+	}
+	return p.content[start:p.pos]
+}
+
+func (p *jsonParser) isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || p.json5 && (c == '\f' || c == '\v')
+}
+
+func (p *jsonParser) isDelimiter(c byte) bool {
+	return p.isWhitespace(c) || c == ',' || c == ':' || c == ']' || c == '}' || p.json5 && c == '/'
+}
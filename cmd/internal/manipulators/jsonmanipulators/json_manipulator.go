@@ -8,6 +8,7 @@ import (
 	"strings"
 )
 
+// JsonManipulator edits JSON files, retaining the key order, indentation, and trailing newline of the original file.
 type JsonManipulator struct {
 	Writer         writers.Writer
 	Reader         readers.Reader
@@ -19,37 +20,90 @@ func (m JsonManipulator) GetFormatName() string {
 }
 
 func (m JsonManipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m JsonManipulator) MatchesFileName(fileSpec string) bool {
+	return strings.HasSuffix(fileSpec, ".json")
+}
+
+func (m JsonManipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m JsonManipulator) ParseError(fileSpec string) error {
 	content, err := m.Reader.ReadString(fileSpec)
 	if err != nil {
-		return false
+		return err
 	}
 
 	var result map[string]any
 	err = json.Unmarshal([]byte(content), &result)
-	return err == nil && strings.HasSuffix(fileSpec, ".json")
+	return err
 }
 
 func (m JsonManipulator) SetValue(fileSpec string, valueSpec string, value string) error {
+	return m.SetTypedValue(fileSpec, valueSpec, value, manipulators.ValueTypeAuto)
+}
+
+func (m JsonManipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
 	content, err := m.Reader.ReadString(fileSpec)
 	if err != nil {
 		return err
 	}
 
 	var result map[string]any
-	err = json.Unmarshal([]byte(content), &result)
+	err = unmarshal(content, &result)
+	if err != nil {
+		return err
+	}
+
+	result, err = m.MapManipulator.ProcessMap(result, valueSpec, value, valueType)
+	if err != nil {
+		return err
+	}
+
+	json, err := m.render(content, result)
+	if err != nil {
+		return err
+	}
+	err = m.Writer.WriteString(fileSpec, json)
+	return err
+}
+
+func (m JsonManipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	var result map[string]any
+	err = unmarshal(content, &result)
 	if err != nil {
 		return err
 	}
 
-	result, err = m.MapManipulator.ProcessMap(result, valueSpec, value)
+	result, err = m.MapManipulator.DeleteFromMap(result, valueSpec)
 	if err != nil {
 		return err
 	}
 
-	json, err := json.Marshal(result)
+	json, err := m.render(content, result)
 	if err != nil {
 		return err
 	}
-	err = m.Writer.WriteString(fileSpec, string(json))
+	err = m.Writer.WriteString(fileSpec, json)
 	return err
 }
+
+// render writes the modified object back into the original content, so only new or changed values are reformatted.
+func (m JsonManipulator) render(content string, result map[string]any) (string, error) {
+	parser := jsonParser{content: content}
+	root, err := parser.parse()
+	if err != nil {
+		return "", err
+	}
+
+	return newJsonRenderer(content, false).renderDocument(root, result)
+}
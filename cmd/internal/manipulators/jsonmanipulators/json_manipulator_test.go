@@ -6,6 +6,7 @@ import (
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
 	"testing"
 )
 
@@ -751,7 +752,7 @@ func TestSetJsonArrayFieldIndexOutOfBounds(t *testing.T) {
 	}
 }
 
-func TestSetJsonArrayFieldAgainstObject(t *testing.T) {
+func TestSetJsonNumericKey(t *testing.T) {
 	jsonExample := "{\"whatever\":{\"hi\":\"there\"}}"
 	writer := writers.StringWriter{}
 	reader := readers.StringReader{
@@ -773,8 +774,42 @@ func TestSetJsonArrayFieldAgainstObject(t *testing.T) {
 
 	err := manipulator.SetValue("/etc/config.json", "whatever:10", "there")
 
+	if err != nil {
+		t.Fatal("Numbers must be treated as keys when used against an object: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	if err != nil {
+		t.Fatal("Failed to parse JSON file: " + err.Error())
+	}
+
+	if result["whatever"].(map[string]any)["10"] != "there" {
+		t.Fatal("The key \"10\" must be set to \"there\"")
+	}
+}
+
+func TestSetJsonQuotedKeyAgainstArray(t *testing.T) {
+	example := "{\"whatever\": [\"there\"]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": example,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "whatever:\"0\"", "there")
+
 	if err == nil {
-		t.Fatal("This should have failed")
+		t.Fatal("Quoted keys must not be used against an array")
 	}
 }
 
@@ -1291,3 +1326,807 @@ func TestSetJsonMissingNestedField(t *testing.T) {
 		t.Fatal("Should have failed to perform replacement")
 	}
 }
+
+func TestSetJsonNewNestedField(t *testing.T) {
+	jsonExample := "{\"whatever\":\"value\"}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "db:primary:host", "newvalue")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	db, ok := result["db"].(map[string]any)
+
+	if !ok {
+		t.Fatal("db must be an object")
+	}
+
+	primary, ok := db["primary"].(map[string]any)
+
+	if !ok {
+		t.Fatal("primary must be an object")
+	}
+
+	value, ok := primary["host"].(string)
+
+	if !ok {
+		t.Fatal("Value must be a string")
+	}
+
+	if value != "newvalue" {
+		t.Fatal("New value must be set to \"newvalue\" (was: \"" + fmt.Sprint(value) + "\"")
+	}
+
+	if result["whatever"] != "value" {
+		t.Fatal("Existing values must be retained")
+	}
+}
+
+func TestSetJsonNestedArrayIndex(t *testing.T) {
+	jsonExample := "{\"servers\":[{\"port\":80},{\"port\":81}]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "servers:1:port", "8080")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	servers, ok := result["servers"].([]any)
+
+	if !ok || len(servers) != 2 {
+		t.Fatal("servers must be an array with two items")
+	}
+
+	server, ok := servers[1].(map[string]any)
+
+	if !ok {
+		t.Fatal("server must be an object")
+	}
+
+	if fmt.Sprint(server["port"]) != "8080" {
+		t.Fatal("Value must be set to \"8080\" (was: \"" + fmt.Sprint(server["port"]) + "\"")
+	}
+
+	server, ok = servers[0].(map[string]any)
+
+	if !ok || fmt.Sprint(server["port"]) != "80" {
+		t.Fatal("Other array items must be retained")
+	}
+}
+
+func TestSetJsonNestedArrayIndexOutOfBounds(t *testing.T) {
+	jsonExample := "{\"servers\":[{\"port\":80},{\"port\":81}]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "servers:5:port", "8080")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+
+	if !strings.Contains(err.Error(), "\"5\"") {
+		t.Fatal("The error must identify the path element (was: \"" + err.Error() + "\"")
+	}
+}
+
+func TestSetJsonArrayAppend(t *testing.T) {
+	jsonExample := "{\"brokers\":[\"a\",\"b\"],\"ports\":[80]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "brokers:-", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a b c]" {
+		t.Fatal("Value must be set to \"[a b c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestSetJsonArrayInsert(t *testing.T) {
+	jsonExample := "{\"brokers\":[\"a\",\"b\"],\"ports\":[80]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "brokers:+1", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c b]" {
+		t.Fatal("Value must be set to \"[a c b]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestSetJsonArrayNegativeIndex(t *testing.T) {
+	jsonExample := "{\"brokers\":[\"a\",\"b\"],\"ports\":[80]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "brokers:-1", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c]" {
+		t.Fatal("Value must be set to \"[a c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestSetJsonArrayNegativeIndexOutOfBounds(t *testing.T) {
+	jsonExample := "{\"brokers\":[\"a\",\"b\"],\"ports\":[80]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "brokers:-3", "c")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+}
+
+func TestSetJsonArrayAppendNewArray(t *testing.T) {
+	jsonExample := "{\"brokers\":[\"a\",\"b\"],\"ports\":[80]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "servers:-:host", "localhost")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	servers, ok := result["servers"].([]any)
+
+	if !ok || len(servers) != 1 {
+		t.Fatal("servers must be an array with one item")
+	}
+
+	server, ok := servers[0].(map[string]any)
+
+	if !ok || server["host"] != "localhost" {
+		t.Fatal("The new item must be an object with the host set to \"localhost\" (was: \"" + fmt.Sprint(servers[0]) + "\"")
+	}
+}
+
+func TestSetJsonArrayAppendRetainsType(t *testing.T) {
+	jsonExample := "{\"brokers\":[\"a\",\"b\"],\"ports\":[80]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.SetValue("/etc/config.json", "ports:-", "81")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	ports, ok := result["ports"].([]any)
+
+	if !ok || len(ports) != 2 {
+		t.Fatal("ports must be an array with two items")
+	}
+
+	if _, ok := ports[1].(string); ok {
+		t.Fatal("The new item must retain the number type of its siblings")
+	}
+}
+
+func TestDeleteJsonNestedField(t *testing.T) {
+	jsonExample := "{\"whatever\":\"value\",\"db\":{\"host\":\"localhost\",\"port\":5432},\"brokers\":[\"a\",\"b\",\"c\"]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.json", "db:host")
+
+	if err != nil {
+		t.Fatal("Failed to delete value: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	db, ok := result["db"].(map[string]any)
+
+	if !ok {
+		t.Fatal("db must be an object")
+	}
+
+	if _, ok := db["host"]; ok {
+		t.Fatal("host must have been deleted")
+	}
+
+	if _, ok := db["port"]; !ok {
+		t.Fatal("port must have been retained")
+	}
+}
+
+func TestDeleteJsonArrayItem(t *testing.T) {
+	jsonExample := "{\"whatever\":\"value\",\"db\":{\"host\":\"localhost\",\"port\":5432},\"brokers\":[\"a\",\"b\",\"c\"]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.json", "brokers:1")
+
+	if err != nil {
+		t.Fatal("Failed to delete value: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c]" {
+		t.Fatal("brokers must be set to \"[a c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestDeleteJsonMissingField(t *testing.T) {
+	jsonExample := "{\"whatever\":\"value\",\"db\":{\"host\":\"localhost\",\"port\":5432},\"brokers\":[\"a\",\"b\",\"c\"]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.json", "missing:host")
+
+	if err != nil {
+		t.Fatal("Deleting a missing value must not fail: " + err.Error())
+	}
+
+	var result map[string]any
+	err = json.Unmarshal([]byte((*writer.Output)["/etc/config.json"]), &result)
+
+	if result["whatever"] != "value" {
+		t.Fatal("Existing values must be retained")
+	}
+}
+
+func TestDeleteJsonAppend(t *testing.T) {
+	jsonExample := "{\"whatever\":\"value\",\"db\":{\"host\":\"localhost\",\"port\":5432},\"brokers\":[\"a\",\"b\",\"c\"]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must be able to manipulate JSON files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.json", "brokers:-")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+}
+
+const indentedJson = `{
+    "server": {
+        "port": 8080,
+        "host": "0.0.0.0"
+    },
+    "brokers": ["kafka1", "kafka2"],
+    "empty": {},
+    "enabled": true
+}
+`
+
+func TestSetJsonPreservesFormatting(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": indentedJson,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"server:port", "9090", strings.Replace(indentedJson, "8080", "9090", 1)},
+		{"server:host", "a&b", strings.Replace(indentedJson, "0.0.0.0", "a&b", 1)},
+		{"enabled", "false", strings.Replace(indentedJson, "true", "false", 1)},
+		{"brokers:1", "kafka3", strings.Replace(indentedJson, "\"kafka2\"", "\"kafka3\"", 1)},
+		{"brokers:+0", "kafka0", strings.Replace(indentedJson, "[\"kafka1\"", "[\"kafka0\", \"kafka1\"", 1)},
+		{"brokers:-", "kafka3", strings.Replace(indentedJson, "\"kafka2\"]", "\"kafka2\", \"kafka3\"]", 1)},
+		{"server:tls", "true", strings.Replace(indentedJson, "\"0.0.0.0\"\n", "\"0.0.0.0\",\n        \"tls\": \"true\"\n", 1)},
+		{"empty:key", "value", strings.Replace(indentedJson, "{}", "{\n        \"key\": \"value\"\n    }", 1)},
+		{"logging:level", "debug", strings.Replace(indentedJson, "true\n}", "true,\n    \"logging\": {\n        \"level\": \"debug\"\n    }\n}", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/config.json", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.json"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the value (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestSetJsonInlineContainer(t *testing.T) {
+	inlineJson := "{\n    \"db\": {\"host\": \"a\", \"port\": 1},\n    \"tags\": [\"x\"]\n}\n"
+
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": inlineJson,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor  string
+		value     string
+		valueType manipulators.ValueType
+		expected  string
+	}{
+		{"db:pool:size", "small", manipulators.ValueTypeAuto, strings.Replace(inlineJson, "\"port\": 1}", "\"port\": 1, \"pool\": {\"size\": \"small\"}}", 1)},
+		{"db:port", "{\"a\":[1,2]}", manipulators.ValueTypeObject, strings.Replace(inlineJson, "\"port\": 1", "\"port\": {\"a\": [1, 2]}", 1)},
+		{"tags:-", "{\"k\":\"v\"}", manipulators.ValueTypeObject, strings.Replace(inlineJson, "[\"x\"]", "[\"x\", {\"k\": \"v\"}]", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetTypedValue("/etc/config.json", test.accessor, test.value, test.valueType)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.json"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must write new values on a single line (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestDeleteJsonPreservesFormatting(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": indentedJson,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		expected string
+	}{
+		{"server:port", strings.Replace(indentedJson, "\n        \"port\": 8080,", "", 1)},
+		{"server:host", strings.Replace(indentedJson, ",\n        \"host\": \"0.0.0.0\"", "", 1)},
+		{"brokers:0", strings.Replace(indentedJson, "\"kafka1\", ", "", 1)},
+		{"enabled", strings.Replace(indentedJson, ",\n    \"enabled\": true", "", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.DeleteValue("/etc/config.json", test.accessor)
+
+		if err != nil {
+			t.Fatal("Failed to delete value: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.json"]
+
+		if output != test.expected {
+			t.Fatal("Deleting " + test.accessor + " must only remove the value (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestSetJsonRetainsIntegers(t *testing.T) {
+	jsonExample := "{\"port\": 8080, \"ratio\": 1.5, \"id\": 9007199254740993, \"ports\": [80], \"limits\": {}}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"port", "9090", strings.Replace(jsonExample, "8080", "9090", 1)},
+		{"ratio", "2.25", strings.Replace(jsonExample, "1.5", "2.25", 1)},
+		{"id", "9007199254740995", strings.Replace(jsonExample, "9007199254740993", "9007199254740995", 1)},
+		{"ports:-", "443", strings.Replace(jsonExample, "[80]", "[80,443]", 1)},
+		{"limits", "{\"max\": 9007199254740993}", strings.Replace(jsonExample, "{}", "{\"max\":9007199254740993}", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/config.json", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.json"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must retain the number type (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestSetJsonTypedValue(t *testing.T) {
+	jsonExample := "{\"code\": \"abc\", \"port\": 8080}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": jsonExample,
+		},
+	}
+	manipulator := JsonManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: JsonUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor  string
+		value     string
+		valueType manipulators.ValueType
+		expected  string
+	}{
+		{"code", "0012", manipulators.ValueTypeString, strings.Replace(jsonExample, "\"abc\"", "\"0012\"", 1)},
+		{"code", "true", manipulators.ValueTypeBool, strings.Replace(jsonExample, "\"abc\"", "true", 1)},
+		{"port", "8080", manipulators.ValueTypeString, strings.Replace(jsonExample, "8080", "\"8080\"", 1)},
+		{"port", "", manipulators.ValueTypeNull, strings.Replace(jsonExample, "8080", "null", 1)},
+		{"timeout", "30", manipulators.ValueTypeInt, strings.Replace(jsonExample, "}", ", \"timeout\": 30}", 1)},
+		{"limits", "{\"max\": 1}", manipulators.ValueTypeObject, strings.Replace(jsonExample, "}", ", \"limits\": {\"max\":1}}", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetTypedValue("/etc/config.json", test.accessor, test.value, test.valueType)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.json"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " as " + test.valueType.String() + " must write the hinted type (was: \"" + output + "\"")
+		}
+	}
+
+	err := manipulator.SetTypedValue("/etc/config.json", "limits", "[1]", manipulators.ValueTypeObject)
+
+	if err == nil {
+		t.Fatal("Setting an array as an object must fail")
+	}
+}
+
+func TestSetJsonFloatFieldWithWholeNumber(t *testing.T) {
+	tests := []struct {
+		existing string
+		value    string
+		expected string
+	}{
+		{"1.0", "2", "{\"whatever\":2.0}"},
+		{"1.5", "3", "{\"whatever\":3.0}"},
+		{"1e3", "2000", "{\"whatever\":2000.0}"},
+		{"1.5", "2.25", "{\"whatever\":2.25}"},
+		{"1", "2", "{\"whatever\":2}"},
+	}
+
+	for _, test := range tests {
+		writer := writers.StringWriter{}
+		reader := readers.StringReader{
+			Files: &map[string]string{
+				"/etc/config.json": "{\"whatever\":" + test.existing + "}",
+			},
+		}
+		manipulator := JsonManipulator{
+			Writer: &writer,
+			Reader: reader,
+			MapManipulator: manipulators.CommonMapManipulator{
+				Unmarshaller: JsonUnmarshaller{},
+			},
+		}
+
+		err := manipulator.SetValue("/etc/config.json", "whatever", test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON file: " + err.Error())
+		}
+
+		if (*writer.Output)["/etc/config.json"] != test.expected {
+			t.Fatal("Setting " + test.existing + " to " + test.value + " must write " + test.expected + " (was: " + (*writer.Output)["/etc/config.json"] + ")")
+		}
+	}
+}
+
+func TestSetJsonLargeIntegerField(t *testing.T) {
+	tests := []struct {
+		accessor  string
+		value     string
+		valueType manipulators.ValueType
+		expected  string
+	}{
+		{"u", "18446744073709551614", manipulators.ValueTypeAuto, "{\"u\":18446744073709551614}"},
+		{"u", "123456789012345678901234567890", manipulators.ValueTypeAuto, "{\"u\":123456789012345678901234567890}"},
+		{"u", "18446744073709551614", manipulators.ValueTypeInt, "{\"u\":18446744073709551614}"},
+		{"u", "{\"id\":18446744073709551614}", manipulators.ValueTypeObject, "{\"u\":{\"id\":18446744073709551614}}"},
+		{"u", "[18446744073709551614]", manipulators.ValueTypeJson, "{\"u\":[18446744073709551614]}"},
+	}
+
+	for _, test := range tests {
+		writer := writers.StringWriter{}
+		reader := readers.StringReader{
+			Files: &map[string]string{
+				"/etc/config.json": "{\"u\":18446744073709551615}",
+			},
+		}
+		manipulator := JsonManipulator{
+			Writer: &writer,
+			Reader: reader,
+			MapManipulator: manipulators.CommonMapManipulator{
+				Unmarshaller: JsonUnmarshaller{},
+			},
+		}
+
+		err := manipulator.SetTypedValue("/etc/config.json", test.accessor, test.value, test.valueType)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate JSON file: " + err.Error())
+		}
+
+		if (*writer.Output)["/etc/config.json"] != test.expected {
+			t.Fatal("Setting " + test.value + " must write " + test.expected + " (was: " + (*writer.Output)["/etc/config.json"] + ")")
+		}
+	}
+}
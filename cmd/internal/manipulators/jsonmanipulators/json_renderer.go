@@ -0,0 +1,504 @@
+package jsonmanipulators
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// jsonRenderer writes a modified value back into the original document. Values that have not changed are copied
+// from the original content, so key order, whitespace, and the formatting of numbers and strings are retained.
+// Only new or modified values are marshalled, using the indentation detected in the original document. JSON5
+// documents retain their comments, trailing commas, and unquoted keys.
+type jsonRenderer struct {
+	content string
+	indent  string
+	json5   bool
+	// inline is set when rendering the children of a container written on a single line, so new values are written
+	// on a single line too
+	inline *inlineStyle
+}
+
+// inlineStyle is the spacing used by a container written on a single line.
+type inlineStyle struct {
+	// colon is the text between a key and its value, including the colon
+	colon string
+	// space is the whitespace after each comma
+	space string
+}
+
+func newJsonRenderer(content string, json5 bool) jsonRenderer {
+	return jsonRenderer{
+		content: content,
+		indent:  detectIndent(content),
+		json5:   json5,
+	}
+}
+
+// renderDocument replaces the root value of the document, retaining any text before and after it.
+func (r jsonRenderer) renderDocument(root *jsonNode, value any) (string, error) {
+	rendered, err := r.render(root, value, "")
+	if err != nil {
+		return "", err
+	}
+
+	return r.content[:root.start] + rendered + r.content[root.end:], nil
+}
+
+// render returns the text of the value, reusing the original text of the node where possible. The prefix is the
+// indentation used by new values.
+func (r jsonRenderer) render(node *jsonNode, value any, prefix string) (string, error) {
+	if node == nil {
+		return r.marshal(value, prefix)
+	}
+
+	raw := r.content[node.start:node.end]
+
+	if existing, err := r.value(node); err == nil && reflect.DeepEqual(existing, value) {
+		return raw, nil
+	}
+
+	switch node.kind {
+	case '{':
+		if object, ok := value.(map[string]any); ok {
+			return r.renderObject(node, object)
+		}
+	case '[':
+		if array, ok := value.([]any); ok {
+			return r.renderArray(node, array)
+		}
+	}
+
+	return r.marshal(value, r.lineIndent(node.start))
+}
+
+// renderedChild is a member or item in the modified document. The original child is nil for new values.
+type renderedChild struct {
+	original *jsonChild
+	first    bool
+	last     bool
+	keyText  string
+	colon    string
+	value    string
+}
+
+func (r jsonRenderer) renderObject(node *jsonNode, object map[string]any) (string, error) {
+	children := []renderedChild{}
+	childIndent := r.childIndent(node)
+	childRenderer := r.forChildren(node)
+	seen := map[string]bool{}
+
+	for i := range node.children {
+		child := &node.children[i]
+		value, ok := object[child.key]
+		if !ok || seen[child.key] {
+			continue
+		}
+		seen[child.key] = true
+
+		rendered, err := childRenderer.render(child.value, value, childIndent)
+		if err != nil {
+			return "", err
+		}
+
+		children = append(children, renderedChild{
+			original: child,
+			first:    i == 0,
+			last:     i == len(node.children)-1,
+			keyText:  child.keyText,
+			colon:    child.colon,
+			value:    rendered,
+		})
+	}
+
+	// New keys are added to the end of the object
+	keys := []string{}
+	for key := range object {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	colon := ":"
+	if len(node.children) != 0 {
+		colon = node.children[len(node.children)-1].colon
+	} else if r.inline != nil {
+		colon = r.inline.colon
+	} else if r.indent != "" {
+		colon = ": "
+	}
+
+	for _, key := range keys {
+		keyText, err := r.keyText(node, key)
+		if err != nil {
+			return "", err
+		}
+
+		rendered, err := childRenderer.marshal(object[key], childIndent)
+		if err != nil {
+			return "", err
+		}
+
+		children = append(children, renderedChild{
+			keyText: keyText,
+			colon:   colon,
+			value:   rendered,
+		})
+	}
+
+	return r.join(node, children, "{", "}"), nil
+}
+
+func (r jsonRenderer) renderArray(node *jsonNode, array []any) (string, error) {
+	existing := make([]any, len(node.children))
+	for i, child := range node.children {
+		value, err := r.value(child.value)
+		if err != nil {
+			return "", err
+		}
+		existing[i] = value
+	}
+
+	// Items that are unchanged at the start and end of the array are retained as is. The items in between are
+	// modified in place, with any extra items removed or added.
+	prefix := 0
+	for prefix < len(existing) && prefix < len(array) && reflect.DeepEqual(existing[prefix], array[prefix]) {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(existing)-prefix && suffix < len(array)-prefix && reflect.DeepEqual(existing[len(existing)-1-suffix], array[len(array)-1-suffix]) {
+		suffix++
+	}
+
+	modifiedExisting := len(existing) - prefix - suffix
+	modifiedNew := len(array) - prefix - suffix
+	paired := modifiedExisting
+	if modifiedNew < paired {
+		paired = modifiedNew
+	}
+
+	children := []renderedChild{}
+	childIndent := r.childIndent(node)
+	childRenderer := r.forChildren(node)
+
+	appendExisting := func(i int, value any) error {
+		child := &node.children[i]
+		rendered, err := childRenderer.render(child.value, value, childIndent)
+		if err != nil {
+			return err
+		}
+
+		children = append(children, renderedChild{
+			original: child,
+			first:    i == 0,
+			last:     i == len(node.children)-1,
+			value:    rendered,
+		})
+		return nil
+	}
+
+	for i := 0; i < prefix+paired; i++ {
+		if err := appendExisting(i, array[i]); err != nil {
+			return "", err
+		}
+	}
+
+	for i := prefix + paired; i < prefix+modifiedNew; i++ {
+		rendered, err := childRenderer.marshal(array[i], childIndent)
+		if err != nil {
+			return "", err
+		}
+
+		children = append(children, renderedChild{value: rendered})
+	}
+
+	for i := 0; i < suffix; i++ {
+		if err := appendExisting(len(existing)-suffix+i, array[len(array)-suffix+i]); err != nil {
+			return "", err
+		}
+	}
+
+	return r.join(node, children, "[", "]"), nil
+}
+
+// join writes the children between the brackets, reusing the original whitespace around each child. New children
+// are separated with the same whitespace as the last child of the original container.
+func (r jsonRenderer) join(node *jsonNode, children []renderedChild, open string, close string) string {
+	if len(children) == 0 {
+		return open + close
+	}
+
+	newline := ""
+	if r.indent != "" {
+		newline = "\n"
+	}
+
+	firstBefore := newline + r.childIndent(node)
+	separator := firstBefore
+	closing := newline + r.lineIndent(node.start)
+	if r.indent == "" {
+		firstBefore = ""
+		separator = ""
+		closing = ""
+	} else if r.inline != nil {
+		firstBefore = ""
+		separator = r.inline.space
+		closing = ""
+	}
+
+	lineComment := ""
+	if len(node.children) != 0 {
+		firstBefore = node.children[0].before
+		separator = r.withoutComments(node.children[len(node.children)-1].before)
+		lineComment, closing = r.splitLineComment(node.closing)
+
+		// A single child on the same line as the bracket gives no hint about the separator
+		if len(node.children) == 1 && !strings.Contains(separator, "\n") && r.indent != "" {
+			separator = " "
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(open)
+	for i, child := range children {
+		final := i == len(children)-1
+
+		if i == 0 && child.original != nil && child.first {
+			builder.WriteString(firstBefore)
+		} else if i == 0 {
+			// Comments before a deleted first child are removed with it
+			builder.WriteString(r.withoutComments(firstBefore))
+		} else if child.original != nil && !child.first {
+			builder.WriteString(child.original.before)
+		} else {
+			builder.WriteString(separator)
+		}
+
+		builder.WriteString(child.keyText)
+		builder.WriteString(child.colon)
+		builder.WriteString(child.value)
+
+		if !final || node.trailingComma {
+			if child.original != nil && (!child.last || node.trailingComma) {
+				builder.WriteString(child.original.after)
+			}
+			builder.WriteString(",")
+		}
+
+		// A comment on the same line as the last child remains on that line when new children are added after it
+		if child.original != nil && child.last && !final {
+			builder.WriteString(lineComment)
+			lineComment = ""
+		}
+	}
+	builder.WriteString(lineComment)
+	builder.WriteString(closing)
+	builder.WriteString(close)
+
+	return builder.String()
+}
+
+// value returns the decoded value of a node in the original document.
+func (r jsonRenderer) value(node *jsonNode) (any, error) {
+	if r.json5 {
+		return decodeJson5(r.content, node)
+	}
+
+	var value any
+	err := unmarshal(r.content[node.start:node.end], &value)
+	return value, err
+}
+
+// keyText returns the text of a new key. Keys in JSON5 documents are left unquoted if the existing keys are unquoted.
+func (r jsonRenderer) keyText(node *jsonNode, key string) (string, error) {
+	if r.json5 && len(node.children) != 0 && r.isIdentifier(key) {
+		last := node.children[len(node.children)-1].keyText
+		if last[0] != '"' && last[0] != '\'' {
+			return key, nil
+		}
+	}
+
+	return r.marshal(key, "")
+}
+
+func (r jsonRenderer) isIdentifier(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if !isIdentifierCharacter(key[i], i == 0) {
+			return false
+		}
+	}
+
+	return key != ""
+}
+
+// withoutComments removes any comments from the text before a child, leaving the line break and indentation.
+func (r jsonRenderer) withoutComments(text string) string {
+	if !strings.Contains(text, "/") {
+		return text
+	}
+
+	index := strings.LastIndex(text, "\n")
+	if index == -1 {
+		return " "
+	}
+
+	line := text[index+1:]
+	return "\n" + line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// splitLineComment splits a comment on the same line as the last child from the text before the closing bracket.
+func (r jsonRenderer) splitLineComment(text string) (string, string) {
+	if index := strings.Index(text, "\n"); index != -1 && strings.Contains(text[:index], "/") {
+		return text[:index], text[index:]
+	}
+
+	return "", text
+}
+
+// forChildren returns the renderer used for the children of a container. Containers written on a single line in an
+// indented document have their new children written on a single line too, with the same spacing as the existing
+// children. Empty containers use the style of their parent.
+func (r jsonRenderer) forChildren(node *jsonNode) jsonRenderer {
+	if r.indent == "" || len(node.children) == 0 {
+		return r
+	}
+
+	if strings.Contains(node.closing, "\n") {
+		r.inline = nil
+		return r
+	}
+
+	for _, child := range node.children {
+		if strings.Contains(child.before, "\n") || strings.Contains(child.after, "\n") {
+			r.inline = nil
+			return r
+		}
+	}
+
+	// A single child gives no hint about the whitespace after each comma
+	space := " "
+	if len(node.children) > 1 {
+		space = r.withoutComments(node.children[len(node.children)-1].before)
+	}
+
+	colon := ":" + space
+	if node.kind == '{' {
+		colon = node.children[len(node.children)-1].colon
+	}
+
+	r.inline = &inlineStyle{colon: colon, space: space}
+	return r
+}
+
+// marshal converts a value to JSON, indenting any objects or arrays if the original document was indented.
+func (r jsonRenderer) marshal(value any, prefix string) (string, error) {
+	if r.inline != nil {
+		return r.marshalInline(value)
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if r.indent != "" {
+		encoder.SetIndent(prefix, r.indent)
+	}
+
+	err := encoder.Encode(value)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+// marshalInline converts a value to JSON on a single line, using the spacing of the inline style.
+func (r jsonRenderer) marshalInline(value any) (string, error) {
+	compact := r
+	compact.indent = ""
+	compact.inline = nil
+
+	items := []string{}
+	switch typedValue := value.(type) {
+	case map[string]any:
+		keys := []string{}
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			keyText, err := compact.marshal(key, "")
+			if err != nil {
+				return "", err
+			}
+
+			item, err := r.marshalInline(typedValue[key])
+			if err != nil {
+				return "", err
+			}
+			items = append(items, keyText+r.inline.colon+item)
+		}
+		return "{" + strings.Join(items, ","+r.inline.space) + "}", nil
+	case []any:
+		for _, item := range typedValue {
+			text, err := r.marshalInline(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, text)
+		}
+		return "[" + strings.Join(items, ","+r.inline.space) + "]", nil
+	default:
+		return compact.marshal(value, "")
+	}
+}
+
+// childIndent returns the indentation of the children of the container.
+func (r jsonRenderer) childIndent(node *jsonNode) string {
+	if len(node.children) != 0 {
+		before := node.children[0].before
+		if index := strings.LastIndex(before, "\n"); index != -1 {
+			return before[index+1:]
+		}
+	}
+
+	return r.lineIndent(node.start) + r.indent
+}
+
+// lineIndent returns the whitespace at the start of the line containing the offset.
+func (r jsonRenderer) lineIndent(offset int) string {
+	start := strings.LastIndex(r.content[:offset], "\n") + 1
+	end := start
+	for end < len(r.content) && (r.content[end] == ' ' || r.content[end] == '\t') {
+		end++
+	}
+
+	return r.content[start:end]
+}
+
+// detectIndent returns the whitespace used by the first indented line, or an empty string if the document is
+// written on a single line.
+func detectIndent(content string) string {
+	if !strings.Contains(strings.TrimSpace(content), "\n") {
+		return ""
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if len(trimmed) != len(line) && strings.TrimSpace(trimmed) != "" {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+
+	return "  "
+}
+
+func unquote(text string) (string, error) {
+	var value string
+	err := json.Unmarshal([]byte(text), &value)
+	return value, err
+}
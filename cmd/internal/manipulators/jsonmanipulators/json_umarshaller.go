@@ -1,13 +1,19 @@
 package jsonmanipulators
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
 
+// JsonUnmarshaller parses JSON values with numbers decoded as json.Number, which retains the exact value of
+// integers and large numbers.
 type JsonUnmarshaller struct {
 }
 
 func (u JsonUnmarshaller) UnmarshalMap(value string) (map[string]any, error) {
 	var objectValue map[string]any
-	err := json.Unmarshal([]byte(value), &objectValue)
+	err := unmarshal(value, &objectValue)
 	if err != nil {
 		return nil, err
 	}
@@ -16,9 +22,55 @@ func (u JsonUnmarshaller) UnmarshalMap(value string) (map[string]any, error) {
 
 func (u JsonUnmarshaller) UnmarshalArray(value string) ([]any, error) {
 	var objectValue []any
-	err := json.Unmarshal([]byte(value), &objectValue)
+	err := unmarshal(value, &objectValue)
 	if err != nil {
 		return nil, err
 	}
 	return objectValue, nil
 }
+
+// unmarshal parses a JSON document, decoding numbers as json.Number.
+func unmarshal(value string, result any) error {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	err := decoder.Decode(result)
+	if err != nil {
+		return err
+	}
+
+	if decoder.More() {
+		return errors.New("unexpected content after the JSON value")
+	}
+
+	return nil
+}
+
+// Json5Unmarshaller parses JSON5 values, which allows values like "{port: 8080}" to be set in JSON5 documents.
+type Json5Unmarshaller struct {
+}
+
+func (u Json5Unmarshaller) UnmarshalMap(value string) (map[string]any, error) {
+	result, err := unmarshalJson5(value)
+	if err != nil {
+		return nil, err
+	}
+
+	objectValue, ok := result.(map[string]any)
+	if !ok {
+		return nil, errors.New("the value is not an object")
+	}
+	return objectValue, nil
+}
+
+func (u Json5Unmarshaller) UnmarshalArray(value string) ([]any, error) {
+	result, err := unmarshalJson5(value)
+	if err != nil {
+		return nil, err
+	}
+
+	arrayValue, ok := result.([]any)
+	if !ok {
+		return nil, errors.New("the value is not an array")
+	}
+	return arrayValue, nil
+}
@@ -3,11 +3,24 @@ package manipulators
 type ValueType int
 
 type Manipulator interface {
+	// CanManipulate returns true if the file name matches the format and the file can be parsed
 	CanManipulate(fileSpec string) bool
+	// MatchesFileName returns true if the file name, usually the extension, is used by the format
+	MatchesFileName(fileSpec string) bool
+	// CanParse returns true if the file can be parsed, regardless of the file name
+	CanParse(fileSpec string) bool
+	// ParseError returns the error reading or parsing the file, or nil if the file can be parsed
+	ParseError(fileSpec string) error
 	SetValue(fileSpec string, valueSpec string, value string) error
+	// SetTypedValue sets the value, converting it to the type hint rather than the type of the existing value
+	SetTypedValue(fileSpec string, valueSpec string, value string, valueType ValueType) error
+	DeleteValue(fileSpec string, valueSpec string) error
 	GetFormatName() string
 }
 
 type MapManipulator interface {
-	ProcessMap(result map[string]any, valueSpec string, value string) (map[string]any, error)
+	ProcessMap(result map[string]any, valueSpec string, value string, valueType ValueType) (map[string]any, error)
+	DeleteFromMap(result map[string]any, valueSpec string) (map[string]any, error)
+	ConvertValue(existing any, value string) any
+	ConvertTypedValue(existing any, value string, valueType ValueType) (any, error)
 }
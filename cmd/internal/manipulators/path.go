@@ -0,0 +1,53 @@
+package manipulators
+
+import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"strings"
+)
+
+// SplitPath splits an accessor like "a:b:0" into its elements. Colons and brackets that are part of a key are
+// escaped with a backslash e.g. "endpoints:urls\:http" or "hosts:fe80\:\:1". Elements wrapped in double quotes
+// are always treated as keys, and may contain unescaped colons e.g. "hosts:\"fe80::1\"". The quotes are retained
+// in the returned elements, and are removed with PathKey.
+func SplitPath(valueSpec string) []string {
+	path := []string{}
+	start := 0
+	quoted := false
+	for i := 0; i < len(valueSpec); i++ {
+		if valueSpec[i] == '\\' && i+1 < len(valueSpec) && stringutil.IsEscapable(valueSpec[i+1]) {
+			i++
+		} else if valueSpec[i] == '"' && (i == start || quoted) {
+			quoted = !quoted
+		} else if valueSpec[i] == ':' && !quoted {
+			path = append(path, stringutil.Unescape(valueSpec[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(path, stringutil.Unescape(valueSpec[start:]))
+}
+
+// JoinPath joins path elements into an accessor, escaping any special characters. It is the reverse of SplitPath.
+func JoinPath(path []string) string {
+	escaped := []string{}
+	for _, p := range path {
+		escaped = append(escaped, stringutil.Escape(p))
+	}
+
+	return strings.Join(escaped, ":")
+}
+
+// IsQuotedKey returns true if the path element is wrapped in double quotes, which forces the element to be treated
+// as a key rather than an array index.
+func IsQuotedKey(element string) bool {
+	return len(element) >= 2 && element[0] == '"' && element[len(element)-1] == '"'
+}
+
+// PathKey returns the key referenced by a path element, removing any quotes.
+func PathKey(element string) string {
+	if IsQuotedKey(element) {
+		return element[1 : len(element)-1]
+	}
+
+	return element
+}
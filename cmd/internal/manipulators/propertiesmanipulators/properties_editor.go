@@ -0,0 +1,327 @@
+package propertiesmanipulators
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// propertiesEntry records the location of a key/value pair in the original file.
+type propertiesEntry struct {
+	key string
+	// start and end are the offsets of the logical line, which may span many lines ending in a backslash. The end
+	// includes the final line break.
+	start int
+	end   int
+	// valueStart and valueEnd are the offsets of the escaped value
+	valueStart int
+	valueEnd   int
+	// separator is the text between the key and the value e.g. " = "
+	separator string
+}
+
+// propertiesEditor edits a Java properties file in place, so comments, blank lines, key order, and the separators
+// around each value are retained. The file format is described at
+// https://docs.oracle.com/javase/8/docs/api/java/util/Properties.html#load-java.io.Reader-
+type propertiesEditor struct {
+	content string
+	entries []propertiesEntry
+	newline string
+	// escapeUnicode is true if characters outside of ASCII are written as \uXXXX escapes. Files that already
+	// contain unescaped characters are assumed to be read as UTF-8.
+	escapeUnicode bool
+}
+
+func newPropertiesEditor(content string) (*propertiesEditor, error) {
+	editor := propertiesEditor{
+		content:       content,
+		newline:       "\n",
+		escapeUnicode: true,
+	}
+
+	if strings.Contains(content, "\r\n") {
+		editor.newline = "\r\n"
+	}
+
+	for _, c := range content {
+		if c > 0x7e {
+			editor.escapeUnicode = false
+			break
+		}
+	}
+
+	err := editor.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return &editor, nil
+}
+
+func (e *propertiesEditor) String() string {
+	return e.content
+}
+
+func (e *propertiesEditor) parse() error {
+	e.entries = []propertiesEntry{}
+
+	for pos := 0; pos < len(e.content); {
+		start := pos
+		lineEnd := e.lineEnd(pos)
+
+		keyStart := e.skipWhitespace(pos, lineEnd)
+		if keyStart == lineEnd || e.content[keyStart] == '#' || e.content[keyStart] == '!' {
+			pos = e.nextLine(lineEnd)
+			continue
+		}
+
+		// Lines ending in an odd number of backslashes are continued on the next line
+		end := lineEnd
+		for e.isContinued(start, end) && end < len(e.content) {
+			end = e.lineEnd(e.nextLine(end))
+		}
+
+		keyEnd := keyStart
+		for keyEnd < end && !strings.ContainsRune("=: \t\f", rune(e.content[keyEnd])) {
+			if e.content[keyEnd] == '\\' {
+				keyEnd++
+			}
+			keyEnd++
+		}
+		keyEnd = e.min(keyEnd, end)
+
+		valueStart := e.skipWhitespace(keyEnd, end)
+		if valueStart < end && (e.content[valueStart] == '=' || e.content[valueStart] == ':') {
+			valueStart = e.skipWhitespace(valueStart+1, end)
+		}
+
+		line := fmt.Sprint(strings.Count(e.content[:start], "\n") + 1)
+		key, err := e.decode(e.content[keyStart:keyEnd])
+		if err != nil {
+			return errors.New(err.Error() + " in the key on line " + line)
+		}
+
+		if _, err := e.decode(e.content[valueStart:end]); err != nil {
+			return errors.New(err.Error() + " in the value on line " + line)
+		}
+
+		e.entries = append(e.entries, propertiesEntry{
+			key:        key,
+			start:      start,
+			end:        e.nextLine(end),
+			valueStart: valueStart,
+			valueEnd:   end,
+			separator:  e.content[keyEnd:valueStart],
+		})
+
+		pos = e.nextLine(end)
+	}
+
+	return nil
+}
+
+// Value returns the decoded value of the last matching key, which is the value Java uses when a key is repeated.
+func (e *propertiesEditor) Value(key string) (string, bool, error) {
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		if e.entries[i].key == key {
+			value, err := e.decode(e.content[e.entries[i].valueStart:e.entries[i].valueEnd])
+			return value, true, err
+		}
+	}
+
+	return "", false, nil
+}
+
+// SetValue replaces the value of every matching key, or adds the key to the end of the file if it does not exist.
+func (e *propertiesEditor) SetValue(key string, value string) error {
+	found := false
+
+	// Entries are replaced from the end of the file so the offsets of earlier entries are not changed
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key != key {
+			continue
+		}
+		found = true
+
+		separator := ""
+		if entry.separator == "" {
+			separator = e.newSeparator()
+		}
+		e.content = e.content[:entry.valueStart] + separator + e.escape(value, false) + e.content[entry.valueEnd:]
+	}
+
+	if !found {
+		if e.content != "" && !strings.HasSuffix(e.content, "\n") && !strings.HasSuffix(e.content, "\r") {
+			e.content += e.newline
+		}
+		e.content += e.escape(key, true) + e.newSeparator() + e.escape(value, false) + e.newline
+	}
+
+	return e.parse()
+}
+
+// DeleteKey removes every matching key.
+func (e *propertiesEditor) DeleteKey(key string) error {
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.key == key {
+			e.content = e.content[:entry.start] + e.content[entry.end:]
+		}
+	}
+
+	return e.parse()
+}
+
+// newSeparator uses the same separator as the last key in the file.
+func (e *propertiesEditor) newSeparator() string {
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		if e.entries[i].separator != "" {
+			return e.entries[i].separator
+		}
+	}
+
+	return "="
+}
+
+// escape converts a key or value to the format written to a properties file, in the same way as
+// java.util.Properties.store.
+func (e *propertiesEditor) escape(text string, isKey bool) string {
+	var builder strings.Builder
+	for i, c := range text {
+		switch {
+		case c == '\\':
+			builder.WriteString("\\\\")
+		case c == '\t':
+			builder.WriteString("\\t")
+		case c == '\n':
+			builder.WriteString("\\n")
+		case c == '\r':
+			builder.WriteString("\\r")
+		case c == '\f':
+			builder.WriteString("\\f")
+		case c == ' ' && (isKey || i == 0):
+			// Spaces separate keys from values, and leading spaces in a value are ignored
+			builder.WriteString("\\ ")
+		case isKey && strings.ContainsRune("=:#!", c):
+			builder.WriteString("\\" + string(c))
+		case c < 0x20 || c > 0x7e && e.escapeUnicode:
+			for _, unit := range utf16.Encode([]rune{c}) {
+				builder.WriteString(fmt.Sprintf("\\u%04X", unit))
+			}
+		default:
+			builder.WriteRune(c)
+		}
+	}
+
+	return builder.String()
+}
+
+// decode removes the escape sequences and line continuations from a key or value.
+func (e *propertiesEditor) decode(text string) (string, error) {
+	var builder strings.Builder
+	units := []uint16{}
+
+	flushUnits := func() {
+		builder.WriteString(string(utf16.Decode(units)))
+		units = []uint16{}
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 >= len(text) {
+			flushUnits()
+			builder.WriteByte(text[i])
+			continue
+		}
+
+		i++
+		if text[i] == 'u' {
+			if i+5 > len(text) {
+				return "", errors.New("malformed \\uxxxx encoding")
+			}
+			unit, err := strconv.ParseUint(text[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", errors.New("malformed \\uxxxx encoding")
+			}
+			// Characters outside of the basic multilingual plane are written as a pair of escapes
+			units = append(units, uint16(unit))
+			i += 4
+			continue
+		}
+
+		flushUnits()
+		switch text[i] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case '\r', '\n':
+			// A line continuation, which skips the line break and the leading whitespace of the next line
+			if text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			for i+1 < len(text) && strings.ContainsRune(" \t\f", rune(text[i+1])) {
+				i++
+			}
+		default:
+			builder.WriteByte(text[i])
+		}
+	}
+	flushUnits()
+
+	return builder.String(), nil
+}
+
+// isContinued returns true if the line ends with an odd number of backslashes.
+func (e *propertiesEditor) isContinued(start int, end int) bool {
+	count := 0
+	for i := end - 1; i >= start && e.content[i] == '\\'; i-- {
+		count++
+	}
+
+	return count%2 == 1
+}
+
+// lineEnd returns the offset of the line break that ends the line containing the offset.
+func (e *propertiesEditor) lineEnd(pos int) int {
+	if index := strings.IndexAny(e.content[pos:], "\r\n"); index != -1 {
+		return pos + index
+	}
+
+	return len(e.content)
+}
+
+// nextLine returns the offset of the line after the line break at the offset.
+func (e *propertiesEditor) nextLine(lineEnd int) int {
+	if lineEnd >= len(e.content) {
+		return len(e.content)
+	}
+
+	if strings.HasPrefix(e.content[lineEnd:], "\r\n") {
+		return lineEnd + 2
+	}
+
+	return lineEnd + 1
+}
+
+func (e *propertiesEditor) skipWhitespace(pos int, end int) int {
+	for pos < end && strings.ContainsRune(" \t\f", rune(e.content[pos])) {
+		pos++
+	}
+
+	return pos
+}
+
+func (e *propertiesEditor) min(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
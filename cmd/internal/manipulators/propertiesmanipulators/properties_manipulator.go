@@ -0,0 +1,101 @@
+package propertiesmanipulators
+
+import (
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+)
+
+// PropertiesManipulator edits Java properties files, like those used by Spring Boot and Kafka. Properties files
+// are flat, so the accessor is the full name of the property e.g. "server.port".
+type PropertiesManipulator struct {
+	Writer writers.Writer
+	Reader readers.Reader
+}
+
+func (m PropertiesManipulator) GetFormatName() string {
+	return "Properties"
+}
+
+func (m PropertiesManipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m PropertiesManipulator) MatchesFileName(fileSpec string) bool {
+	return strings.HasSuffix(fileSpec, ".properties")
+}
+
+func (m PropertiesManipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m PropertiesManipulator) ParseError(fileSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	_, err = newPropertiesEditor(content)
+	return err
+}
+
+func (m PropertiesManipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
+	if err := manipulators.ValidateTextValue(m.GetFormatName(), value, valueType); err != nil {
+		return err
+	}
+
+	return m.SetValue(fileSpec, valueSpec, value)
+}
+
+func (m PropertiesManipulator) SetValue(fileSpec string, valueSpec string, value string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	editor, err := newPropertiesEditor(content)
+	if err != nil {
+		return err
+	}
+
+	key := m.getKey(valueSpec)
+	if key == "" {
+		return errors.New("path must include a key to set")
+	}
+
+	err = editor.SetValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	return m.Writer.WriteString(fileSpec, editor.String())
+}
+
+func (m PropertiesManipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	editor, err := newPropertiesEditor(content)
+	if err != nil {
+		return err
+	}
+
+	err = editor.DeleteKey(m.getKey(valueSpec))
+	if err != nil {
+		return err
+	}
+
+	return m.Writer.WriteString(fileSpec, editor.String())
+}
+
+// getKey returns the property name. Colons do not separate path elements in properties files, so they are
+// treated as part of the key.
+func (m PropertiesManipulator) getKey(valueSpec string) string {
+	return manipulators.PathKey(stringutil.Unescape(valueSpec))
+}
@@ -0,0 +1,182 @@
+package propertiesmanipulators
+
+import (
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"testing"
+)
+
+func TestPropertiesInvalidFileExtension(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.ini": "server.port=8080",
+		},
+	}
+	manipulator := PropertiesManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	if manipulator.CanManipulate("/etc/config.ini") {
+		t.Fatal("Must not be able to process files other that .properties")
+	}
+}
+
+func TestPropertiesInvalidUnicodeEscape(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/application.properties": "greeting=\\u00",
+		},
+	}
+	manipulator := PropertiesManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	if manipulator.CanManipulate("/etc/application.properties") {
+		t.Fatal("Must not be able to process files with malformed unicode escapes")
+	}
+}
+
+func TestPropertiesSetPreservesFormatting(t *testing.T) {
+	propertiesExample := "# Server settings\n" +
+		"server.port=8080\n" +
+		"\n" +
+		"! Database settings\n" +
+		"spring.datasource.url: jdbc:mysql://localhost/db\n" +
+		"message = hello \\\n" +
+		"    world\n" +
+		"greeting = caf\\u00e9\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/application.properties": propertiesExample,
+		},
+	}
+	manipulator := PropertiesManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	if !manipulator.CanManipulate("/etc/application.properties") {
+		t.Fatal("Must be able to manipulate properties files")
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"server.port", "9090", "# Server settings\n" +
+			"server.port=9090\n" +
+			"\n" +
+			"! Database settings\n" +
+			"spring.datasource.url: jdbc:mysql://localhost/db\n" +
+			"message = hello \\\n" +
+			"    world\n" +
+			"greeting = caf\\u00e9\n"},
+		{"spring.datasource.url", "jdbc:postgresql://db/app", "# Server settings\n" +
+			"server.port=8080\n" +
+			"\n" +
+			"! Database settings\n" +
+			"spring.datasource.url: jdbc:postgresql://db/app\n" +
+			"message = hello \\\n" +
+			"    world\n" +
+			"greeting = caf\\u00e9\n"},
+		{"message", "goodbye", "# Server settings\n" +
+			"server.port=8080\n" +
+			"\n" +
+			"! Database settings\n" +
+			"spring.datasource.url: jdbc:mysql://localhost/db\n" +
+			"message = goodbye\n" +
+			"greeting = caf\\u00e9\n"},
+		{"logging.level.root", "DEBUG", propertiesExample + "logging.level.root = DEBUG\n"},
+		{"my key:name", " café\\", propertiesExample + "my\\ key\\:name = \\ caf\\u00E9\\\\\n"},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/application.properties", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate properties file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/application.properties"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the matching line (was: \"" + output + "\")")
+		}
+
+		editor, err := newPropertiesEditor(output)
+
+		if err != nil {
+			t.Fatal("Failed to parse the modified properties file: " + err.Error())
+		}
+
+		value, ok, err := editor.Value(test.accessor)
+
+		if err != nil || !ok || value != test.value {
+			t.Fatal("The modified file must contain the new value for " + test.accessor + " (was: \"" + value + "\")")
+		}
+	}
+}
+
+func TestPropertiesSetRepeatedKey(t *testing.T) {
+	propertiesExample := "port 80\r\nport 8080\r\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/application.properties": propertiesExample,
+		},
+	}
+	manipulator := PropertiesManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.SetValue("/etc/application.properties", "port", "9090")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate properties file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/application.properties"]
+
+	if output != "port 9090\r\nport 9090\r\n" {
+		t.Fatal("Every matching key must be set (was: \"" + output + "\")")
+	}
+}
+
+func TestPropertiesDeletePreservesFormatting(t *testing.T) {
+	propertiesExample := "# Greeting\nmessage = hello \\\n    world\nserver.port=8080"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/application.properties": propertiesExample,
+		},
+	}
+	manipulator := PropertiesManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.DeleteValue("/etc/application.properties", "message")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate properties file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/application.properties"]
+
+	if output != "# Greeting\nserver.port=8080" {
+		t.Fatal("The key and its continuation lines must be deleted (was: \"" + output + "\")")
+	}
+
+	err = manipulator.DeleteValue("/etc/application.properties", "missing")
+
+	if err != nil {
+		t.Fatal("Deleting a missing key must not fail: " + err.Error())
+	}
+}
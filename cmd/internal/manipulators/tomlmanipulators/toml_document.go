@@ -0,0 +1,342 @@
+package tomlmanipulators
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tomlEntry records the location of a table header or a key/value pair in the original document.
+type tomlEntry struct {
+	// path is the full path to the table or value. Items in arrays of tables are identified by their index.
+	path []string
+	// section is the path of the table that contains a key/value pair
+	section    []string
+	header     bool
+	arrayTable bool
+	// lineStart and lineEnd are the offsets of the lines that make up the entry, including the final line break
+	lineStart int
+	lineEnd   int
+	// valueStart and valueEnd are the offsets of the value of a key/value pair
+	valueStart int
+	valueEnd   int
+}
+
+// tomlParser scans a document that is known to be valid TOML, recording the location of each table and value.
+type tomlParser struct {
+	content string
+	pos     int
+	entries []tomlEntry
+}
+
+func (p *tomlParser) parse() ([]tomlEntry, error) {
+	section := []string{}
+	arrayTables := map[string]int{}
+
+	for p.pos < len(p.content) {
+		lineStart := p.pos
+		p.skipWhitespace()
+
+		if p.pos >= len(p.content) {
+			break
+		}
+
+		switch p.content[p.pos] {
+		case '\r', '\n', '#':
+			p.skipLine()
+		case '[':
+			arrayTable := strings.HasPrefix(p.content[p.pos:], "[[")
+			if arrayTable {
+				p.pos += 2
+			} else {
+				p.pos++
+			}
+
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+
+			if arrayTable && !strings.HasPrefix(p.content[p.pos:], "]]") || !arrayTable && !strings.HasPrefix(p.content[p.pos:], "]") {
+				return nil, errors.New("expected the end of a table header at offset " + fmt.Sprint(p.pos))
+			}
+			p.pos++
+			if arrayTable {
+				p.pos++
+			}
+
+			// Headers that reference an array of tables refer to the last item in the array
+			section = []string{}
+			for i, key := range keys {
+				section = append(section, key)
+				id := strings.Join(section, "\x00")
+				if arrayTable && i == len(keys)-1 {
+					arrayTables[id]++
+					section = append(section, fmt.Sprint(arrayTables[id]-1))
+				} else if count, ok := arrayTables[id]; ok {
+					section = append(section, fmt.Sprint(count-1))
+				}
+			}
+
+			p.skipLine()
+			p.entries = append(p.entries, tomlEntry{
+				path:       section,
+				header:     true,
+				arrayTable: arrayTable,
+				lineStart:  lineStart,
+				lineEnd:    p.pos,
+			})
+		default:
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+
+			if p.pos >= len(p.content) || p.content[p.pos] != '=' {
+				return nil, errors.New("expected an equals sign at offset " + fmt.Sprint(p.pos))
+			}
+			p.pos++
+			p.skipWhitespace()
+
+			valueStart := p.pos
+			err = p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			valueEnd := p.pos
+
+			p.skipLine()
+			p.entries = append(p.entries, tomlEntry{
+				path:       append(append([]string{}, section...), keys...),
+				section:    section,
+				lineStart:  lineStart,
+				lineEnd:    p.pos,
+				valueStart: valueStart,
+				valueEnd:   valueEnd,
+			})
+		}
+	}
+
+	return p.entries, nil
+}
+
+// parseKey parses a dotted key, returning the individual keys. Whitespace after the key is skipped.
+func (p *tomlParser) parseKey() ([]string, error) {
+	keys := []string{}
+	for {
+		p.skipWhitespace()
+		if p.pos >= len(p.content) {
+			return nil, errors.New("unexpected end of TOML document")
+		}
+
+		start := p.pos
+		switch p.content[p.pos] {
+		case '"':
+			err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			key, err := strconv.Unquote(p.content[start:p.pos])
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case '\'':
+			err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, p.content[start+1:p.pos-1])
+		default:
+			for p.pos < len(p.content) && isBareKeyChar(p.content[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, errors.New("expected a key at offset " + fmt.Sprint(p.pos))
+			}
+			keys = append(keys, p.content[start:p.pos])
+		}
+
+		p.skipWhitespace()
+		if p.pos >= len(p.content) || p.content[p.pos] != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseValue() error {
+	if p.pos >= len(p.content) {
+		return errors.New("unexpected end of TOML document")
+	}
+
+	switch p.content[p.pos] {
+	case '"', '\'':
+		return p.parseString()
+	case '[':
+		p.pos++
+		for {
+			p.skipWhitespaceAndComments()
+			if p.pos >= len(p.content) {
+				return errors.New("unexpected end of TOML document")
+			}
+			if p.content[p.pos] == ']' {
+				p.pos++
+				return nil
+			}
+			if err := p.parseValue(); err != nil {
+				return err
+			}
+			p.skipWhitespaceAndComments()
+			if p.pos < len(p.content) && p.content[p.pos] == ',' {
+				p.pos++
+			}
+		}
+	case '{':
+		p.pos++
+		for {
+			p.skipWhitespace()
+			if p.pos >= len(p.content) {
+				return errors.New("unexpected end of TOML document")
+			}
+			if p.content[p.pos] == '}' {
+				p.pos++
+				return nil
+			}
+			if _, err := p.parseKey(); err != nil {
+				return err
+			}
+			if p.pos >= len(p.content) || p.content[p.pos] != '=' {
+				return errors.New("expected an equals sign at offset " + fmt.Sprint(p.pos))
+			}
+			p.pos++
+			p.skipWhitespace()
+			if err := p.parseValue(); err != nil {
+				return err
+			}
+			p.skipWhitespace()
+			if p.pos < len(p.content) && p.content[p.pos] == ',' {
+				p.pos++
+			}
+		}
+	default:
+		// Numbers, booleans, and dates. Dates may contain a space between the date and the time.
+		start := p.pos
+		for p.pos < len(p.content) && !strings.ContainsRune("\r\n#,]}", rune(p.content[p.pos])) {
+			p.pos++
+		}
+		for p.pos > start && (p.content[p.pos-1] == ' ' || p.content[p.pos-1] == '\t') {
+			p.pos--
+		}
+		if p.pos == start {
+			return errors.New("expected a value at offset " + fmt.Sprint(p.pos))
+		}
+		return nil
+	}
+}
+
+// findArrayItem returns the offsets of the item at the index in the array that starts at the offset.
+func (p *tomlParser) findArrayItem(start int, index int) (int, int, error) {
+	p.pos = start
+	if p.pos >= len(p.content) || p.content[p.pos] != '[' {
+		return 0, 0, errors.New("expected an array at offset " + fmt.Sprint(start))
+	}
+	p.pos++
+
+	for i := 0; ; i++ {
+		p.skipWhitespaceAndComments()
+		if p.pos >= len(p.content) || p.content[p.pos] == ']' {
+			return 0, 0, errors.New("the array at offset " + fmt.Sprint(start) + " does not have an item at index " + fmt.Sprint(index))
+		}
+
+		itemStart := p.pos
+		if err := p.parseValue(); err != nil {
+			return 0, 0, err
+		}
+		if i == index {
+			return itemStart, p.pos, nil
+		}
+
+		p.skipWhitespaceAndComments()
+		if p.pos < len(p.content) && p.content[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
+
+// parseString moves past a basic, literal, or multi-line string.
+func (p *tomlParser) parseString() error {
+	quote := p.content[p.pos : p.pos+1]
+	multiline := strings.HasPrefix(p.content[p.pos:], strings.Repeat(quote, 3))
+	start := p.pos
+
+	if multiline {
+		p.pos += 3
+	} else {
+		p.pos++
+	}
+
+	for p.pos < len(p.content) {
+		c := p.content[p.pos]
+		if c == '\\' && quote == "\"" {
+			p.pos += 2
+			continue
+		}
+
+		if multiline && strings.HasPrefix(p.content[p.pos:], strings.Repeat(quote, 3)) {
+			p.pos += 3
+			// Up to two quotes are allowed immediately before the closing delimiter
+			for i := 0; i < 2 && p.pos < len(p.content) && p.content[p.pos] == quote[0]; i++ {
+				p.pos++
+			}
+			return nil
+		}
+
+		if !multiline && c == quote[0] {
+			p.pos++
+			return nil
+		}
+
+		if !multiline && c == '\n' {
+			break
+		}
+
+		p.pos++
+	}
+
+	return errors.New("unterminated string at offset " + fmt.Sprint(start))
+}
+
+func (p *tomlParser) skipWhitespace() {
+	for p.pos < len(p.content) && (p.content[p.pos] == ' ' || p.content[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) skipWhitespaceAndComments() {
+	for p.pos < len(p.content) {
+		switch p.content[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+// skipLine moves to the start of the next line.
+func (p *tomlParser) skipLine() {
+	for p.pos < len(p.content) && p.content[p.pos] != '\n' {
+		p.pos++
+	}
+	if p.pos < len(p.content) {
+		p.pos++
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
@@ -0,0 +1,577 @@
+package tomlmanipulators
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/pelletier/go-toml/v2"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var bareKey = regexp.MustCompile("^[A-Za-z0-9_-]+$")
+
+// errUnsupportedEdit indicates the change can not be made to the original document, and the document must be
+// marshalled instead.
+var errUnsupportedEdit = errors.New("the change can not be made to the original TOML document")
+
+// tomlEdit replaces the text between start and end. Edits where start and end are the same insert text.
+type tomlEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// tomlEditor compares the original and modified values, and makes the smallest possible changes to the original
+// document. Comments, blank lines, table order, and the layout of unchanged values are retained.
+type tomlEditor struct {
+	content string
+	entries []tomlEntry
+	result  map[string]any
+	edits   []tomlEdit
+	newline string
+}
+
+func newTomlEditor(content string, result map[string]any) (*tomlEditor, error) {
+	parser := tomlParser{content: content}
+	entries, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+
+	return &tomlEditor{
+		content: content,
+		entries: entries,
+		result:  result,
+		newline: newline,
+	}, nil
+}
+
+// edit returns the original document modified to match the result.
+func (e *tomlEditor) edit(original map[string]any) (string, error) {
+	err := e.diff([]string{}, original, e.result)
+	if err != nil {
+		return "", err
+	}
+
+	sort.SliceStable(e.edits, func(i, j int) bool {
+		return e.edits[i].start < e.edits[j].start
+	})
+
+	var builder strings.Builder
+	position := 0
+	for i, edit := range e.edits {
+		// Changes to many values in the same inline table replace the inline table many times
+		if i != 0 && edit == e.edits[i-1] {
+			continue
+		}
+		if edit.start < position {
+			return "", errUnsupportedEdit
+		}
+		builder.WriteString(e.content[position:edit.start])
+		builder.WriteString(edit.text)
+		position = edit.end
+	}
+	builder.WriteString(e.content[position:])
+
+	return builder.String(), nil
+}
+
+func (e *tomlEditor) diff(path []string, existing any, value any) error {
+	if reflect.DeepEqual(existing, value) {
+		return nil
+	}
+
+	// Values defined by a key/value pair, or nested in an inline table or array, are replaced
+	if entry := e.findValue(path); entry != nil {
+		if e.pathEquals(entry.path, path) {
+			return e.replaceItems(entry.valueStart, entry.valueEnd, existing, value)
+		}
+		return e.replaceValue(entry)
+	}
+
+	existingMap, existingIsMap := existing.(map[string]any)
+	valueMap, valueIsMap := value.(map[string]any)
+	if existingIsMap && valueIsMap {
+		for _, key := range e.sortedKeys(existingMap) {
+			if _, ok := valueMap[key]; !ok {
+				if err := e.deleteValue(e.child(path, key)); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, key := range e.sortedKeys(valueMap) {
+			childPath := e.child(path, key)
+			if existingValue, ok := existingMap[key]; ok {
+				if err := e.diff(childPath, existingValue, valueMap[key]); err != nil {
+					return err
+				}
+			} else if err := e.addValue(childPath, valueMap[key]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	existingArray, existingIsArray := existing.([]any)
+	valueArray, valueIsArray := value.([]any)
+	if existingIsArray && valueIsArray {
+		return e.diffArrayOfTables(path, existingArray, valueArray)
+	}
+
+	return errUnsupportedEdit
+}
+
+// diffArrayOfTables modifies the tables in an array of tables. Tables can be modified, appended, or deleted.
+func (e *tomlEditor) diffArrayOfTables(path []string, existing []any, value []any) error {
+	common := len(existing)
+	if len(value) < common {
+		common = len(value)
+	}
+
+	if len(value) < len(existing) {
+		// Find the single table that was deleted
+		deleted := 0
+		for deleted < len(value) && reflect.DeepEqual(existing[deleted], value[deleted]) {
+			deleted++
+		}
+		if len(existing)-len(value) != 1 || !reflect.DeepEqual(existing[deleted+1:], value[deleted:]) {
+			return errUnsupportedEdit
+		}
+		return e.deleteTables(e.child(path, fmt.Sprint(deleted)))
+	}
+
+	for i := 0; i < common; i++ {
+		if err := e.diff(e.child(path, fmt.Sprint(i)), existing[i], value[i]); err != nil {
+			return err
+		}
+	}
+
+	// New tables are appended to the end of the file, which is only unambiguous for top level arrays of tables
+	for i := common; i < len(value); i++ {
+		table, ok := value[i].(map[string]any)
+		if !ok || e.containsIndex(path) {
+			return errUnsupportedEdit
+		}
+
+		e.appendText("[[" + e.formatKey(path) + "]]" + e.newline + e.formatTable([]string{}, table))
+	}
+
+	return nil
+}
+
+// replaceValue replaces the value of a key/value pair with the matching value from the result.
+func (e *tomlEditor) replaceValue(entry *tomlEntry) error {
+	value, ok := e.lookup(entry.path)
+	if !ok {
+		return errUnsupportedEdit
+	}
+
+	text, err := e.formatValue(value, e.content[entry.valueStart:entry.valueEnd])
+	if err != nil {
+		return err
+	}
+
+	e.edits = append(e.edits, tomlEdit{start: entry.valueStart, end: entry.valueEnd, text: text})
+	return nil
+}
+
+// replaceItems replaces the value between start and end. Only the changed items of an array that keeps its length are
+// replaced, so the layout and comments of the other items in arrays written over many lines are retained.
+func (e *tomlEditor) replaceItems(start int, end int, existing any, value any) error {
+	existingArray, existingIsArray := existing.([]any)
+	valueArray, valueIsArray := value.([]any)
+	if existingIsArray && valueIsArray && len(existingArray) == len(valueArray) {
+		parser := tomlParser{content: e.content}
+		for i := range valueArray {
+			if reflect.DeepEqual(existingArray[i], valueArray[i]) {
+				continue
+			}
+
+			itemStart, itemEnd, err := parser.findArrayItem(start, i)
+			if err != nil {
+				return err
+			}
+
+			if err := e.replaceItems(itemStart, itemEnd, existingArray[i], valueArray[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	text, err := e.formatValue(value, e.content[start:end])
+	if err != nil {
+		return err
+	}
+
+	e.edits = append(e.edits, tomlEdit{start: start, end: end, text: text})
+	return nil
+}
+
+func (e *tomlEditor) deleteValue(path []string) error {
+	if entry := e.findValue(path); entry != nil {
+		if e.pathEquals(entry.path, path) {
+			e.edits = append(e.edits, tomlEdit{start: entry.lineStart, end: entry.lineEnd})
+			return nil
+		}
+
+		return e.replaceValue(entry)
+	}
+
+	return e.deleteTables(path)
+}
+
+// deleteTables removes the tables, and any key/value pairs, found under the path.
+func (e *tomlEditor) deleteTables(path []string) error {
+	found := false
+	for i := range e.entries {
+		entry := e.entries[i]
+		if !e.hasPrefix(entry.path, path) {
+			continue
+		}
+
+		if entry.header {
+			found = true
+			e.edits = append(e.edits, tomlEdit{start: entry.lineStart, end: e.endOfTable(i)})
+		} else if !e.hasPrefix(entry.section, path) {
+			// Key/value pairs in tables that are not deleted define the path with dotted keys
+			found = true
+			e.edits = append(e.edits, tomlEdit{start: entry.lineStart, end: entry.lineEnd})
+		}
+	}
+
+	if !found {
+		return errUnsupportedEdit
+	}
+
+	return nil
+}
+
+func (e *tomlEditor) addValue(path []string, value any) error {
+	parent := path[:len(path)-1]
+
+	// The parent is an inline table
+	if entry := e.findValue(parent); entry != nil {
+		return e.replaceValue(entry)
+	}
+
+	// New tables in the root of the document are added to the end of the file
+	if table, ok := value.(map[string]any); ok && len(parent) == 0 {
+		e.appendText("[" + e.formatKey(path) + "]" + e.newline + e.formatTable([]string{}, table))
+		return nil
+	}
+
+	section, insertAt, indent := e.findInsertionPoint(parent)
+	if insertAt == -1 {
+		if e.containsIndex(parent) {
+			return errUnsupportedEdit
+		}
+
+		// The parent table is only defined by its sub-tables, so it is added to the end of the file
+		e.appendText("[" + e.formatKey(parent) + "]" + e.newline + e.formatTable([]string{}, map[string]any{path[len(path)-1]: value}))
+		return nil
+	}
+
+	text, err := e.formatValue(value, "")
+	if err != nil {
+		return err
+	}
+
+	e.insertText(insertAt, indent+e.formatKey(path[len(section):])+" = "+text+e.newline)
+	return nil
+}
+
+// findInsertionPoint returns the table that new key/value pairs in the parent are added to, the offset to add them,
+// and the indentation of the existing key/value pairs. The offset is -1 if there is no suitable table.
+func (e *tomlEditor) findInsertionPoint(parent []string) ([]string, int, string) {
+	insertAt := -1
+	indent := ""
+
+	// Values are added after the last key/value pair in the table, or after the table header
+	for _, entry := range e.entries {
+		if entry.header && e.pathEquals(entry.path, parent) {
+			insertAt = entry.lineEnd
+		} else if !entry.header && e.pathEquals(entry.section, parent) {
+			insertAt = entry.lineEnd
+			indent = e.indentation(entry.lineStart)
+		}
+	}
+
+	if insertAt != -1 {
+		return parent, insertAt, indent
+	}
+
+	if len(parent) == 0 {
+		// Values in the root of the document are added before the first table
+		for _, entry := range e.entries {
+			if entry.header {
+				return parent, entry.lineStart, ""
+			}
+		}
+
+		return parent, len(e.content), ""
+	}
+
+	// The parent may be defined with dotted keys, in which case new values are added with dotted keys as well
+	var section []string
+	for _, entry := range e.entries {
+		if !entry.header && len(entry.path) > len(parent) && e.hasPrefix(entry.path, parent) && e.hasPrefix(parent, entry.section) {
+			section = entry.section
+			insertAt = entry.lineEnd
+			indent = e.indentation(entry.lineStart)
+		}
+	}
+
+	return section, insertAt, indent
+}
+
+// endOfTable returns the offset after the last key/value pair in the table, including any blank lines that
+// follow it. Comments before the next table are retained.
+func (e *tomlEditor) endOfTable(index int) int {
+	end := e.entries[index].lineEnd
+	for _, entry := range e.entries[index+1:] {
+		if entry.header {
+			break
+		}
+		end = entry.lineEnd
+	}
+
+	for end < len(e.content) {
+		lineEnd := strings.Index(e.content[end:], "\n")
+		if lineEnd == -1 {
+			lineEnd = len(e.content) - end
+		} else {
+			lineEnd++
+		}
+
+		if strings.TrimSpace(e.content[end:end+lineEnd]) != "" {
+			break
+		}
+		end += lineEnd
+	}
+
+	return end
+}
+
+// insertText inserts text at the start of a line, adding a line break to the previous line if required.
+func (e *tomlEditor) insertText(offset int, text string) {
+	if offset == len(e.content) && offset != 0 && !strings.HasSuffix(e.content, "\n") {
+		text = e.newline + text
+	}
+
+	e.edits = append(e.edits, tomlEdit{start: offset, end: offset, text: text})
+}
+
+// appendText adds text to the end of the file, separated from the existing content by a blank line.
+func (e *tomlEditor) appendText(text string) {
+	trimmed := strings.TrimRight(e.content, " \t\r\n")
+	if trimmed == "" {
+		e.insertText(len(e.content), text)
+		return
+	}
+
+	prefix := e.newline
+	if !strings.HasSuffix(e.content, "\n") {
+		prefix = e.newline + e.newline
+	} else if strings.HasSuffix(strings.TrimRight(e.content, " \t"), e.newline+e.newline) {
+		prefix = ""
+	}
+
+	e.edits = append(e.edits, tomlEdit{start: len(e.content), end: len(e.content), text: prefix + text})
+}
+
+// formatTable writes the values of a table as key/value pairs, using dotted keys for nested tables.
+func (e *tomlEditor) formatTable(prefix []string, table map[string]any) string {
+	var builder strings.Builder
+	for _, key := range e.sortedKeys(table) {
+		path := append(append([]string{}, prefix...), key)
+		if nested, ok := table[key].(map[string]any); ok && len(nested) != 0 {
+			builder.WriteString(e.formatTable(path, nested))
+			continue
+		}
+
+		text, err := e.formatValue(table[key], "")
+		if err != nil {
+			continue
+		}
+		builder.WriteString(e.formatKey(path) + " = " + text + e.newline)
+	}
+
+	return builder.String()
+}
+
+// formatValue writes a value inline, matching the quotes and the spacing inside the braces of the original value.
+func (e *tomlEditor) formatValue(value any, original string) (string, error) {
+	doubleQuotes := !strings.Contains(original, "'") || strings.Contains(original, "\"") && strings.Index(original, "\"") < strings.Index(original, "'")
+	braceSpace := strings.Contains(original, "{ ")
+	return e.formatInline(value, doubleQuotes, braceSpace)
+}
+
+func (e *tomlEditor) formatInline(value any, doubleQuotes bool, braceSpace bool) (string, error) {
+	switch typedValue := value.(type) {
+	case string:
+		if !doubleQuotes && !strings.ContainsAny(typedValue, "'\r\n") {
+			return "'" + typedValue + "'", nil
+		}
+
+		var buffer bytes.Buffer
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(typedValue); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buffer.String(), "\n"), nil
+	case []any:
+		items := []string{}
+		for _, item := range typedValue {
+			text, err := e.formatInline(item, doubleQuotes, braceSpace)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, text)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]any:
+		if len(typedValue) == 0 {
+			return "{}", nil
+		}
+
+		members := []string{}
+		for _, key := range e.sortedKeys(typedValue) {
+			text, err := e.formatInline(typedValue[key], doubleQuotes, braceSpace)
+			if err != nil {
+				return "", err
+			}
+			members = append(members, e.formatKey([]string{key})+" = "+text)
+		}
+
+		if braceSpace {
+			return "{ " + strings.Join(members, ", ") + " }", nil
+		}
+		return "{" + strings.Join(members, ", ") + "}", nil
+	default:
+		// Numbers, booleans, and dates are written by the TOML encoder
+		var buffer bytes.Buffer
+		err := toml.NewEncoder(&buffer).Encode(map[string]any{"value": value})
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSuffix(strings.TrimPrefix(buffer.String(), "value = "), "\n"), nil
+	}
+}
+
+func (e *tomlEditor) formatKey(path []string) string {
+	keys := []string{}
+	for _, key := range path {
+		if bareKey.MatchString(key) {
+			keys = append(keys, key)
+		} else {
+			quoted, _ := json.Marshal(key)
+			keys = append(keys, string(quoted))
+		}
+	}
+
+	return strings.Join(keys, ".")
+}
+
+// findValue returns the key/value pair that defines the path, or that contains the path in an inline table or array.
+func (e *tomlEditor) findValue(path []string) *tomlEntry {
+	for i := range e.entries {
+		entry := &e.entries[i]
+		if !entry.header && len(path) != 0 && e.hasPrefix(path, entry.path) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// lookup returns the value found at the path in the result.
+func (e *tomlEditor) lookup(path []string) (any, bool) {
+	var current any = e.result
+	for _, key := range path {
+		switch container := current.(type) {
+		case map[string]any:
+			value, ok := container[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []any:
+			index, _, err := manipulators.ResolveArrayIndex(key, len(container))
+			if err != nil {
+				return nil, false
+			}
+			current = container[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// indentation returns the whitespace at the start of the line.
+func (e *tomlEditor) indentation(lineStart int) string {
+	end := lineStart
+	for end < len(e.content) && (e.content[end] == ' ' || e.content[end] == '\t') {
+		end++
+	}
+
+	return e.content[lineStart:end]
+}
+
+func (e *tomlEditor) containsIndex(path []string) bool {
+	for _, key := range path {
+		if manipulators.IsArrayIndex(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *tomlEditor) child(path []string, key string) []string {
+	return append(append([]string{}, path...), key)
+}
+
+func (e *tomlEditor) hasPrefix(path []string, prefix []string) bool {
+	return len(path) >= len(prefix) && e.pathEquals(path[:len(prefix)], prefix)
+}
+
+func (e *tomlEditor) pathEquals(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *tomlEditor) sortedKeys(values map[string]any) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,13 +1,16 @@
 package tomlmanipulators
 
 import (
+	"errors"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"github.com/pelletier/go-toml/v2"
+	"reflect"
 	"strings"
 )
 
+// TomlManipulator edits TOML files in place, so comments, table order, and inline tables are retained.
 type TomlManipulator struct {
 	Writer         writers.Writer
 	Reader         readers.Reader
@@ -19,17 +22,38 @@ func (m TomlManipulator) GetFormatName() string {
 }
 
 func (m TomlManipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m TomlManipulator) MatchesFileName(fileSpec string) bool {
+	return strings.HasSuffix(fileSpec, ".toml")
+}
+
+func (m TomlManipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m TomlManipulator) ParseError(fileSpec string) error {
 	content, err := m.Reader.ReadString(fileSpec)
 	if err != nil {
-		return false
+		return err
 	}
 
 	var result map[string]any
 	err = toml.Unmarshal([]byte(content), &result)
-	return err == nil && strings.HasSuffix(fileSpec, ".toml")
+	return err
 }
 
 func (m TomlManipulator) SetValue(fileSpec string, valueSpec string, value string) error {
+	return m.SetTypedValue(fileSpec, valueSpec, value, manipulators.ValueTypeAuto)
+}
+
+func (m TomlManipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
+	if valueType == manipulators.ValueTypeNull {
+		return errors.New("TOML does not support null values")
+	}
+
 	content, err := m.Reader.ReadString(fileSpec)
 	if err != nil {
 		return err
@@ -41,15 +65,74 @@ func (m TomlManipulator) SetValue(fileSpec string, valueSpec string, value strin
 		return err
 	}
 
-	result, err = m.MapManipulator.ProcessMap(result, valueSpec, value)
+	result, err = m.MapManipulator.ProcessMap(result, valueSpec, value, valueType)
 	if err != nil {
 		return err
 	}
 
-	json, err := toml.Marshal(result)
+	json, err := m.render(content, result)
 	if err != nil {
 		return err
 	}
-	err = m.Writer.WriteString(fileSpec, string(json))
+	err = m.Writer.WriteString(fileSpec, json)
 	return err
 }
+
+func (m TomlManipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte(content), &result)
+	if err != nil {
+		return err
+	}
+
+	result, err = m.MapManipulator.DeleteFromMap(result, valueSpec)
+	if err != nil {
+		return err
+	}
+
+	json, err := m.render(content, result)
+	if err != nil {
+		return err
+	}
+	err = m.Writer.WriteString(fileSpec, json)
+	return err
+}
+
+// render applies the changes in the result to the original content. If the changes can not be applied to the
+// original content, the result is marshalled instead.
+func (m TomlManipulator) render(content string, result map[string]any) (string, error) {
+	marshalled, err := toml.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	var original map[string]any
+	err = toml.Unmarshal([]byte(content), &original)
+	if err != nil {
+		return "", err
+	}
+
+	editor, err := newTomlEditor(content, result)
+	if err != nil {
+		return string(marshalled), nil
+	}
+
+	edited, err := editor.edit(original)
+	if err != nil {
+		return string(marshalled), nil
+	}
+
+	// Only accept the edited content if it is parsed to the same values as the marshalled result
+	var expected map[string]any
+	var actual map[string]any
+	if toml.Unmarshal(marshalled, &expected) != nil || toml.Unmarshal([]byte(edited), &actual) != nil || !reflect.DeepEqual(expected, actual) {
+		return string(marshalled), nil
+	}
+
+	return edited, nil
+}
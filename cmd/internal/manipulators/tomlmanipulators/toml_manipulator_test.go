@@ -6,6 +6,7 @@ import (
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"github.com/pelletier/go-toml/v2"
+	"strings"
 	"testing"
 )
 
@@ -166,10 +167,10 @@ func TestTomlSetNumberField(t *testing.T) {
 	var result map[string]any
 	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
 
-	value, ok := result["whatever"].(float64)
+	value, ok := result["whatever"].(int64)
 
 	if !ok {
-		t.Fatal("Value must be a float")
+		t.Fatal("Value must be an int")
 	}
 
 	if value != 6 {
@@ -713,10 +714,10 @@ func TestTomlSetNumberArrayFieldIndexWithNumber(t *testing.T) {
 		t.Fatal("Value must be an array")
 	}
 
-	value2, ok := value[0].(float64)
+	value2, ok := value[0].(int64)
 
 	if !ok {
-		t.Fatal("Nested Value must be a int")
+		t.Fatal("Nested Value must be an int")
 	}
 
 	if value2 != 20 {
@@ -751,7 +752,7 @@ func TestTomlSetArrayFieldIndexOutOfBounds(t *testing.T) {
 	}
 }
 
-func TestTomlSetArrayFieldAgainstObject(t *testing.T) {
+func TestTomlSetNumericKey(t *testing.T) {
 	tomlExample := "[whatever]\nhi= \"there\""
 	writer := writers.StringWriter{}
 	reader := readers.StringReader{
@@ -773,8 +774,42 @@ func TestTomlSetArrayFieldAgainstObject(t *testing.T) {
 
 	err := manipulator.SetValue("/etc/config.toml", "whatever:10", "there")
 
+	if err != nil {
+		t.Fatal("Numbers must be treated as keys when used against an object: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	if err != nil {
+		t.Fatal("Failed to parse TOML file: " + err.Error())
+	}
+
+	if result["whatever"].(map[string]any)["10"] != "there" {
+		t.Fatal("The key \"10\" must be set to \"there\"")
+	}
+}
+
+func TestTomlSetQuotedKeyAgainstArray(t *testing.T) {
+	example := "whatever = [\"there\"]"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": example,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "whatever:\"0\"", "there")
+
 	if err == nil {
-		t.Fatal("This should have failed")
+		t.Fatal("Quoted keys must not be used against an array")
 	}
 }
 
@@ -932,10 +967,10 @@ func TestTomlSetIntArrayFieldIndexWithInt(t *testing.T) {
 		t.Fatal("Value must be an array")
 	}
 
-	value2, ok := value[0].(float64)
+	value2, ok := value[0].(int64)
 
 	if !ok {
-		t.Fatal("Nested Value must be a number")
+		t.Fatal("Nested Value must be an int")
 	}
 
 	if value2 != 20 {
@@ -1291,3 +1326,720 @@ func TestTomlSetMissingNestedField(t *testing.T) {
 		t.Fatal("Should have failed to perform replacement")
 	}
 }
+
+func TestTomlSetNewNestedField(t *testing.T) {
+	tomlExample := "whatever= \"value\""
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "db:primary:host", "newvalue")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	db, ok := result["db"].(map[string]any)
+
+	if !ok {
+		t.Fatal("db must be an object")
+	}
+
+	primary, ok := db["primary"].(map[string]any)
+
+	if !ok {
+		t.Fatal("primary must be an object")
+	}
+
+	value, ok := primary["host"].(string)
+
+	if !ok {
+		t.Fatal("Value must be a string")
+	}
+
+	if value != "newvalue" {
+		t.Fatal("New value must be set to \"newvalue\" (was: \"" + fmt.Sprint(value) + "\"")
+	}
+
+	if result["whatever"] != "value" {
+		t.Fatal("Existing values must be retained")
+	}
+}
+
+func TestTomlSetNestedArrayIndex(t *testing.T) {
+	tomlExample := "[[servers]]\nport = 80\n[[servers]]\nport = 81"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "servers:1:port", "8080")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	servers, ok := result["servers"].([]any)
+
+	if !ok || len(servers) != 2 {
+		t.Fatal("servers must be an array with two items")
+	}
+
+	server, ok := servers[1].(map[string]any)
+
+	if !ok {
+		t.Fatal("server must be an object")
+	}
+
+	if fmt.Sprint(server["port"]) != "8080" {
+		t.Fatal("Value must be set to \"8080\" (was: \"" + fmt.Sprint(server["port"]) + "\"")
+	}
+
+	server, ok = servers[0].(map[string]any)
+
+	if !ok || fmt.Sprint(server["port"]) != "80" {
+		t.Fatal("Other array items must be retained")
+	}
+}
+
+func TestTomlSetNestedArrayIndexOutOfBounds(t *testing.T) {
+	tomlExample := "[[servers]]\nport = 80\n[[servers]]\nport = 81"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "servers:5:port", "8080")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+
+	if !strings.Contains(err.Error(), "\"5\"") {
+		t.Fatal("The error must identify the path element (was: \"" + err.Error() + "\"")
+	}
+}
+
+func TestTomlSetArrayAppend(t *testing.T) {
+	tomlExample := "brokers = [\"a\", \"b\"]\nports = [80]"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "brokers:-", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a b c]" {
+		t.Fatal("Value must be set to \"[a b c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestTomlSetArrayInsert(t *testing.T) {
+	tomlExample := "brokers = [\"a\", \"b\"]\nports = [80]"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "brokers:+1", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c b]" {
+		t.Fatal("Value must be set to \"[a c b]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestTomlSetArrayNegativeIndex(t *testing.T) {
+	tomlExample := "brokers = [\"a\", \"b\"]\nports = [80]"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "brokers:-1", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c]" {
+		t.Fatal("Value must be set to \"[a c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestTomlSetArrayNegativeIndexOutOfBounds(t *testing.T) {
+	tomlExample := "brokers = [\"a\", \"b\"]\nports = [80]"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "brokers:-3", "c")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+}
+
+func TestTomlSetArrayAppendNewArray(t *testing.T) {
+	tomlExample := "brokers = [\"a\", \"b\"]\nports = [80]"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "servers:-:host", "localhost")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	servers, ok := result["servers"].([]any)
+
+	if !ok || len(servers) != 1 {
+		t.Fatal("servers must be an array with one item")
+	}
+
+	server, ok := servers[0].(map[string]any)
+
+	if !ok || server["host"] != "localhost" {
+		t.Fatal("The new item must be an object with the host set to \"localhost\" (was: \"" + fmt.Sprint(servers[0]) + "\"")
+	}
+}
+
+func TestTomlDeleteNestedField(t *testing.T) {
+	tomlExample := "whatever = \"value\"\nbrokers = [\"a\", \"b\", \"c\"]\n[db]\nhost = \"localhost\"\nport = 5432"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.toml", "db:host")
+
+	if err != nil {
+		t.Fatal("Failed to delete value: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	db, ok := result["db"].(map[string]any)
+
+	if !ok {
+		t.Fatal("db must be an object")
+	}
+
+	if _, ok := db["host"]; ok {
+		t.Fatal("host must have been deleted")
+	}
+
+	if _, ok := db["port"]; !ok {
+		t.Fatal("port must have been retained")
+	}
+}
+
+func TestTomlDeleteArrayItem(t *testing.T) {
+	tomlExample := "whatever = \"value\"\nbrokers = [\"a\", \"b\", \"c\"]\n[db]\nhost = \"localhost\"\nport = 5432"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.toml", "brokers:1")
+
+	if err != nil {
+		t.Fatal("Failed to delete value: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c]" {
+		t.Fatal("brokers must be set to \"[a c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestTomlDeleteMissingField(t *testing.T) {
+	tomlExample := "whatever = \"value\"\nbrokers = [\"a\", \"b\", \"c\"]\n[db]\nhost = \"localhost\"\nport = 5432"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.toml", "missing:host")
+
+	if err != nil {
+		t.Fatal("Deleting a missing value must not fail: " + err.Error())
+	}
+
+	var result map[string]any
+	err = toml.Unmarshal([]byte((*writer.Output)["/etc/config.toml"]), &result)
+
+	if result["whatever"] != "value" {
+		t.Fatal("Existing values must be retained")
+	}
+}
+
+func TestTomlDeleteAppend(t *testing.T) {
+	tomlExample := "whatever = \"value\"\nbrokers = [\"a\", \"b\", \"c\"]\n[db]\nhost = \"localhost\"\nport = 5432"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.toml") {
+		t.Fatal("Must be able to manipulate TOML files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.toml", "brokers:-")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+}
+
+const commentedToml = `# Service configuration
+title = "My service" # the display name
+
+[server]
+host = "0.0.0.0"
+enabled = true
+tags = ["a", "b"]
+
+# Database settings
+[database]
+connection = { host = "localhost", user = "admin" }
+
+[[servers]]
+name = 'alpha'
+
+[[servers]]
+name = 'beta'
+`
+
+func TestTomlSetPreservesFormatting(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": commentedToml,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"title", "Other", strings.Replace(commentedToml, "\"My service\"", "\"Other\"", 1)},
+		{"server:host", "127.0.0.1", strings.Replace(commentedToml, "0.0.0.0", "127.0.0.1", 1)},
+		{"server:enabled", "false", strings.Replace(commentedToml, "enabled = true", "enabled = false", 1)},
+		{"server:tags:-", "c", strings.Replace(commentedToml, "[\"a\", \"b\"]", "[\"a\", \"b\", \"c\"]", 1)},
+		{"server:mode", "fast", strings.Replace(commentedToml, "tags = [\"a\", \"b\"]\n", "tags = [\"a\", \"b\"]\nmode = \"fast\"\n", 1)},
+		{"database:connection:user", "root", strings.Replace(commentedToml, "user = \"admin\"", "user = \"root\"", 1)},
+		{"servers:1:name", "gamma", strings.Replace(commentedToml, "'beta'", "'gamma'", 1)},
+		{"version", "v1", strings.Replace(commentedToml, "# the display name\n", "# the display name\nversion = \"v1\"\n", 1)},
+		{"logging:level", "debug", commentedToml + "\n[logging]\nlevel = \"debug\"\n"},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/config.toml", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate TOML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.toml"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the value (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestTomlSetArrayItemPreservesFormatting(t *testing.T) {
+	arrays := "arr = [\n  1, # one\n  2,\n]\nnested = [[1, 2], ['a', 'b']]\n"
+
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": arrays,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"arr:1", "5", strings.Replace(arrays, "  2,", "  5,", 1)},
+		{"arr:0", "7", strings.Replace(arrays, "  1, # one", "  7, # one", 1)},
+		{"nested:1:0", "c", strings.Replace(arrays, "'a'", "'c'", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/config.toml", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate TOML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.toml"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the array item (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestTomlDeletePreservesFormatting(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": commentedToml,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		expected string
+	}{
+		{"server:enabled", strings.Replace(commentedToml, "enabled = true\n", "", 1)},
+		{"server", strings.Replace(commentedToml, "[server]\nhost = \"0.0.0.0\"\nenabled = true\ntags = [\"a\", \"b\"]\n\n", "", 1)},
+		{"database:connection:user", strings.Replace(commentedToml, ", user = \"admin\"", "", 1)},
+		{"servers:0", strings.Replace(commentedToml, "[[servers]]\nname = 'alpha'\n\n", "", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.DeleteValue("/etc/config.toml", test.accessor)
+
+		if err != nil {
+			t.Fatal("Failed to delete value: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.toml"]
+
+		if output != test.expected {
+			t.Fatal("Deleting " + test.accessor + " must only remove the value (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestTomlSetRetainsIntegers(t *testing.T) {
+	tomlExample := "port = 8080\nratio = 1.5\nid = 9007199254740993\nports = [80]\n[limits]\nmax = 1\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"port", "9090", strings.Replace(tomlExample, "8080", "9090", 1)},
+		{"ratio", "2", strings.Replace(tomlExample, "1.5", "2.0", 1)},
+		{"id", "9007199254740995", strings.Replace(tomlExample, "9007199254740993", "9007199254740995", 1)},
+		{"ports:-", "443", strings.Replace(tomlExample, "[80]", "[80, 443]", 1)},
+		{"limits", "{\"max\": 9007199254740993}", strings.Replace(tomlExample, "max = 1", "max = 9007199254740993", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/config.toml", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate TOML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.toml"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must retain the number type (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestTomlSetTypedValue(t *testing.T) {
+	tomlExample := "code = \"abc\"\nport = 8080\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor  string
+		value     string
+		valueType manipulators.ValueType
+		expected  string
+	}{
+		{"code", "12", manipulators.ValueTypeInt, strings.Replace(tomlExample, "\"abc\"", "12", 1)},
+		{"port", "0012", manipulators.ValueTypeString, strings.Replace(tomlExample, "8080", "\"0012\"", 1)},
+		{"port", "2", manipulators.ValueTypeFloat, strings.Replace(tomlExample, "8080", "2.0", 1)},
+		{"enabled", "true", manipulators.ValueTypeBool, tomlExample + "enabled = true\n"},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetTypedValue("/etc/config.toml", test.accessor, test.value, test.valueType)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate TOML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.toml"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " as " + test.valueType.String() + " must write the hinted type (was: \"" + output + "\"")
+		}
+	}
+
+	err := manipulator.SetTypedValue("/etc/config.toml", "port", "", manipulators.ValueTypeNull)
+
+	if err == nil {
+		t.Fatal("Setting a null value must fail")
+	}
+}
+
+func TestTomlSetNumericKeyPreservesFormatting(t *testing.T) {
+	tomlExample := "# Error pages\n[pages]\n404 = \"missing.html\"\n500 = \"error.html\"\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.toml": tomlExample,
+		},
+	}
+	manipulator := TomlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: TomlUnmarshaller{},
+		},
+	}
+
+	err := manipulator.SetValue("/etc/config.toml", "pages:404", "notfound.html")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate TOML file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/config.toml"]
+
+	if output != strings.Replace(tomlExample, "missing.html", "notfound.html", 1) {
+		t.Fatal("The numeric key must be modified in place (was: \"" + output + "\"")
+	}
+}
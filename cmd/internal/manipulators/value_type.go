@@ -0,0 +1,88 @@
+package manipulators
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+const (
+	// ValueTypeAuto matches the type of the existing value
+	ValueTypeAuto ValueType = iota
+	ValueTypeString
+	ValueTypeInt
+	ValueTypeFloat
+	ValueTypeBool
+	ValueTypeNull
+	ValueTypeObject
+	ValueTypeArray
+	// ValueTypeJson parses the value as any JSON value
+	ValueTypeJson
+)
+
+var valueTypeNames = map[string]ValueType{
+	"string": ValueTypeString,
+	"int":    ValueTypeInt,
+	"float":  ValueTypeFloat,
+	"bool":   ValueTypeBool,
+	"null":   ValueTypeNull,
+	"object": ValueTypeObject,
+	"array":  ValueTypeArray,
+	"json":   ValueTypeJson,
+}
+
+// ParseValueType converts a type hint like "int" or "bool" to a ValueType. The returned boolean is false if the
+// name is not a known type.
+func ParseValueType(name string) (ValueType, bool) {
+	valueType, ok := valueTypeNames[name]
+	return valueType, ok
+}
+
+// ValueTypeNames returns the names of the type hints, in alphabetical order.
+func ValueTypeNames() []string {
+	names := []string{}
+	for name := range valueTypeNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (t ValueType) String() string {
+	for name, valueType := range valueTypeNames {
+		if valueType == t {
+			return name
+		}
+	}
+
+	return "auto"
+}
+
+// ValidateTextValue checks a value against its type hint for formats that store every value as text, like XML, INI,
+// Java properties, and dotenv files. The value is written as is, so a hint like "int" only checks that the value is
+// an int. The null, object, array, and json hints are errors, as these formats can not store structured values.
+func ValidateTextValue(format string, value string, valueType ValueType) error {
+	switch valueType {
+	case ValueTypeAuto, ValueTypeString:
+		return nil
+	case ValueTypeInt:
+		_, err := strconv.ParseInt(value, 10, 64)
+		if _, unsignedErr := strconv.ParseUint(value, 10, 64); err != nil && unsignedErr != nil {
+			return errors.New("the value \"" + value + "\" is not an int")
+		}
+		return nil
+	case ValueTypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.New("the value \"" + value + "\" is not a float")
+		}
+		return nil
+	case ValueTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("the value \"" + value + "\" is not a bool")
+		}
+		return nil
+	}
+
+	return errors.New(format + " files store every value as text, so the type hint \"" + valueType.String() + "\" can not be used")
+}
@@ -0,0 +1,162 @@
+package xmlmanipulators
+
+import (
+	"errors"
+	"github.com/beevik/etree"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/stringutil"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"regexp"
+	"strings"
+)
+
+// attributeAccessor matches an XPath whose final step selects an attribute, e.g. /configuration/add[@key='Db']/@value
+var attributeAccessor = regexp.MustCompile("^(.+)/@([^/\\[\\]='\"]+)$")
+
+// XmlManipulator uses XPath style accessors to set element text and attribute values in XML files.
+type XmlManipulator struct {
+	Writer writers.Writer
+	Reader readers.Reader
+}
+
+func (m XmlManipulator) GetFormatName() string {
+	return "XML"
+}
+
+func (m XmlManipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m XmlManipulator) MatchesFileName(fileSpec string) bool {
+	return strings.HasSuffix(fileSpec, ".xml") || strings.HasSuffix(fileSpec, ".config")
+}
+
+func (m XmlManipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m XmlManipulator) ParseError(fileSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromString(content)
+	if err != nil {
+		return err
+	}
+
+	if doc.Root() == nil {
+		return errors.New("the file has no root element")
+	}
+
+	return nil
+}
+
+func (m XmlManipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
+	if err := manipulators.ValidateTextValue(m.GetFormatName(), value, valueType); err != nil {
+		return err
+	}
+
+	return m.SetValue(fileSpec, valueSpec, value)
+}
+
+func (m XmlManipulator) SetValue(fileSpec string, valueSpec string, value string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromString(content)
+	if err != nil {
+		return err
+	}
+
+	elementPath, attribute := m.splitAccessor(valueSpec)
+
+	path, err := etree.CompilePath(elementPath)
+	if err != nil {
+		return err
+	}
+
+	elements := doc.FindElementsPath(path)
+	if len(elements) == 0 {
+		return errors.New("the path " + elementPath + " did not match any elements")
+	}
+
+	// Like XPath, the accessor may match many elements, and all of them are updated
+	for _, element := range elements {
+		if attribute != "" {
+			element.CreateAttr(attribute, value)
+		} else {
+			element.SetText(value)
+		}
+	}
+
+	xml, err := doc.WriteToString()
+	if err != nil {
+		return err
+	}
+	err = m.Writer.WriteString(fileSpec, xml)
+	return err
+}
+
+// DeleteValue removes the elements, or the attributes, matched by the accessor. Accessors that match nothing are
+// ignored, so deleting a value is safe to repeat.
+func (m XmlManipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromString(content)
+	if err != nil {
+		return err
+	}
+
+	elementPath, attribute := m.splitAccessor(valueSpec)
+
+	path, err := etree.CompilePath(elementPath)
+	if err != nil {
+		return err
+	}
+
+	for _, element := range doc.FindElementsPath(path) {
+		if attribute != "" {
+			element.RemoveAttr(attribute)
+		} else if parent := element.Parent(); parent != nil {
+			// Remove the indentation before the element to avoid leaving a blank line
+			index := element.Index()
+			parent.RemoveChildAt(index)
+			if index > 0 {
+				if whitespace, ok := parent.Child[index-1].(*etree.CharData); ok && whitespace.IsWhitespace() {
+					parent.RemoveChildAt(index - 1)
+				}
+			}
+		}
+	}
+
+	xml, err := doc.WriteToString()
+	if err != nil {
+		return err
+	}
+	err = m.Writer.WriteString(fileSpec, xml)
+	return err
+}
+
+// splitAccessor splits an accessor into the path to the elements and the optional attribute name.
+// A trailing text() step is accepted and treated the same as selecting the element.
+func (m XmlManipulator) splitAccessor(valueSpec string) (string, string) {
+	valueSpec = stringutil.Unescape(valueSpec)
+	rs := attributeAccessor.FindStringSubmatch(valueSpec)
+	if rs != nil && len(rs) == 3 {
+		return rs[1], rs[2]
+	}
+
+	return strings.TrimSuffix(valueSpec, "/text()"), ""
+}
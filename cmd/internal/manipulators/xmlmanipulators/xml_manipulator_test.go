@@ -0,0 +1,345 @@
+package xmlmanipulators
+
+import (
+	"github.com/beevik/etree"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
+	"strings"
+	"testing"
+)
+
+const webConfig = `<?xml version="1.0" encoding="utf-8"?>
+<!-- Application settings -->
+<configuration>
+  <appSettings>
+    <add key="Db" value="localhost" />
+    <add key="Port" value="1433" />
+  </appSettings>
+  <logLevel>Info</logLevel>
+</configuration>
+`
+
+func TestXmlInvalidFile(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/web.config": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	if manipulator.CanManipulate("/etc/config.doesnotexist") {
+		t.Fatal("This should have failed")
+	}
+}
+
+func TestXmlInvalidXml(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.xml": "<configuration><unclosed></configuration>",
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	if manipulator.CanManipulate("/etc/config.xml") {
+		t.Fatal("Must not be able to manipulate invalid XML files")
+	}
+}
+
+func TestXmlInvalidFileExtension(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.json": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	if manipulator.CanManipulate("/etc/config.json") {
+		t.Fatal("Must not be able to process files other that .xml or .config")
+	}
+}
+
+func TestXmlSetAttribute(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/web.config": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	if !manipulator.CanManipulate("/etc/web.config") {
+		t.Fatal("Must be able to manipulate XML files")
+	}
+
+	err := manipulator.SetValue("/etc/web.config", "/configuration/appSettings/add[@key='Db']/@value", "db.example.org")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate XML file: " + err.Error())
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromString((*writer.Output)["/etc/web.config"])
+
+	if err != nil {
+		t.Fatal("Failed to parse XML file: " + err.Error())
+	}
+
+	value := doc.FindElement("/configuration/appSettings/add[@key='Db']").SelectAttrValue("value", "")
+
+	if value != "db.example.org" {
+		t.Fatal("Value must be set to \"db.example.org\" (was: \"" + value + "\"")
+	}
+
+	value = doc.FindElement("/configuration/appSettings/add[@key='Port']").SelectAttrValue("value", "")
+
+	if value != "1433" {
+		t.Fatal("Value must be left as \"1433\" (was: \"" + value + "\"")
+	}
+}
+
+func TestXmlSetNewAttribute(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/web.config": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.SetValue("/etc/web.config", "/configuration/logLevel/@override", "true")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate XML file: " + err.Error())
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromString((*writer.Output)["/etc/web.config"])
+
+	if err != nil {
+		t.Fatal("Failed to parse XML file: " + err.Error())
+	}
+
+	value := doc.FindElement("/configuration/logLevel").SelectAttrValue("override", "")
+
+	if value != "true" {
+		t.Fatal("Value must be set to \"true\" (was: \"" + value + "\"")
+	}
+}
+
+func TestXmlSetElementText(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/log4j2.xml": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	if !manipulator.CanManipulate("/etc/log4j2.xml") {
+		t.Fatal("Must be able to manipulate XML files")
+	}
+
+	err := manipulator.SetValue("/etc/log4j2.xml", "/configuration/logLevel", "Debug & Trace")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate XML file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/log4j2.xml"]
+	doc := etree.NewDocument()
+	err = doc.ReadFromString(output)
+
+	if err != nil {
+		t.Fatal("Failed to parse XML file: " + err.Error())
+	}
+
+	value := doc.FindElement("/configuration/logLevel").Text()
+
+	if value != "Debug & Trace" {
+		t.Fatal("Value must be set to \"Debug & Trace\" (was: \"" + value + "\"")
+	}
+
+	if !strings.Contains(output, "<!-- Application settings -->") {
+		t.Fatal("Comments must be retained")
+	}
+}
+
+func TestXmlSetElementTextFunction(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.xml": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.SetValue("/etc/config.xml", "//logLevel/text()", "Warn")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate XML file: " + err.Error())
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromString((*writer.Output)["/etc/config.xml"])
+
+	if err != nil {
+		t.Fatal("Failed to parse XML file: " + err.Error())
+	}
+
+	value := doc.FindElement("/configuration/logLevel").Text()
+
+	if value != "Warn" {
+		t.Fatal("Value must be set to \"Warn\" (was: \"" + value + "\"")
+	}
+}
+
+func TestXmlSetMultipleElements(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.xml": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.SetValue("/etc/config.xml", "//add/@value", "changed")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate XML file: " + err.Error())
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromString((*writer.Output)["/etc/config.xml"])
+
+	if err != nil {
+		t.Fatal("Failed to parse XML file: " + err.Error())
+	}
+
+	for _, element := range doc.FindElements("//add") {
+		if value := element.SelectAttrValue("value", ""); value != "changed" {
+			t.Fatal("Value must be set to \"changed\" (was: \"" + value + "\"")
+		}
+	}
+}
+
+func TestXmlSetMissingElement(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.xml": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.SetValue("/etc/config.xml", "/configuration/appSettings/add[@key='Missing']/@value", "newvalue")
+
+	if err == nil {
+		t.Fatal("Should have failed to perform replacement")
+	}
+}
+
+func TestXmlSetInvalidPath(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.xml": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.SetValue("/etc/config.xml", "/configuration/add[@key", "newvalue")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+}
+
+func TestXmlDeleteAttribute(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/web.config": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.DeleteValue("/etc/web.config", "/configuration/appSettings/add[@key='Db']/@value")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate XML file: " + err.Error())
+	}
+
+	doc := etree.NewDocument()
+	err = doc.ReadFromString((*writer.Output)["/etc/web.config"])
+
+	if err != nil {
+		t.Fatal("Failed to parse XML file: " + err.Error())
+	}
+
+	if doc.FindElement("/configuration/appSettings/add[@key='Db']").SelectAttr("value") != nil {
+		t.Fatal("Attribute must have been deleted")
+	}
+}
+
+func TestXmlDeleteElement(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/web.config": webConfig,
+		},
+	}
+	manipulator := XmlManipulator{
+		Writer: &writer,
+		Reader: reader,
+	}
+
+	err := manipulator.DeleteValue("/etc/web.config", "/configuration/appSettings/add[@key='Db']")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate XML file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/web.config"]
+	expected := strings.Replace(webConfig, "    <add key=\"Db\" value=\"localhost\" />\n", "", 1)
+	expected = strings.Replace(expected, " />", "/>", -1)
+
+	if output != expected {
+		t.Fatal("Element must have been deleted (was: \"" + output + "\"")
+	}
+}
@@ -1,13 +1,26 @@
 package yamlmanipulators
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"gopkg.in/yaml.v3"
+	"io"
+	"reflect"
+	"regexp"
 	"strings"
 )
 
+// documentPattern matches the first path element when it selects a document in a stream of YAML documents e.g. "#1".
+var documentPattern = regexp.MustCompile("^#-?[0-9]+$")
+
+// YamlManipulator edits YAML documents as a tree of yaml.Node objects, which retains comments, key order,
+// and anchors. Changes to a single scalar value are written back to the original file content, leaving the rest
+// of the file untouched. Files with many documents separated by "---" are edited one document at a time, so the
+// other documents are not changed.
 type YamlManipulator struct {
 	Writer         writers.Writer
 	Reader         readers.Reader
@@ -19,37 +32,302 @@ func (m YamlManipulator) GetFormatName() string {
 }
 
 func (m YamlManipulator) CanManipulate(fileSpec string) bool {
+	return m.MatchesFileName(fileSpec) && m.CanParse(fileSpec)
+}
+
+func (m YamlManipulator) MatchesFileName(fileSpec string) bool {
+	return strings.HasSuffix(fileSpec, ".yml") || strings.HasSuffix(fileSpec, ".yaml")
+}
+
+func (m YamlManipulator) CanParse(fileSpec string) bool {
+	return m.ParseError(fileSpec) == nil
+}
+
+// ParseError returns the error reading or parsing the file, or nil if the file can be parsed.
+func (m YamlManipulator) ParseError(fileSpec string) error {
 	content, err := m.Reader.ReadString(fileSpec)
 	if err != nil {
-		return false
+		return err
+	}
+
+	// Every document in the file must be an object
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var result map[string]any
+		err = decoder.Decode(&result)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
 	}
 
-	var result map[string]any
-	err = yaml.Unmarshal([]byte(content), &result)
-	return err == nil && (strings.HasSuffix(fileSpec, ".yml") || strings.HasSuffix(fileSpec, ".yaml"))
+	return nil
 }
 
 func (m YamlManipulator) SetValue(fileSpec string, valueSpec string, value string) error {
+	return m.SetTypedValue(fileSpec, valueSpec, value, manipulators.ValueTypeAuto)
+}
+
+func (m YamlManipulator) SetTypedValue(fileSpec string, valueSpec string, value string, valueType manipulators.ValueType) error {
 	content, err := m.Reader.ReadString(fileSpec)
 	if err != nil {
 		return err
 	}
 
-	var result map[string]any
-	err = yaml.Unmarshal([]byte(content), &result)
+	documents := m.splitDocuments(content)
+	index, path, err := m.selectDocument(manipulators.SplitPath(valueSpec), len(documents))
+	if err != nil {
+		return err
+	}
+
+	if len(path) == 0 {
+		return errors.New("path must include a key to set after the document index")
+	}
+
+	doc, err := m.parseDocument(documents[index])
 	if err != nil {
 		return err
 	}
 
-	result, err = m.MapManipulator.ProcessMap(result, valueSpec, value)
+	edit, err := m.setNode(doc.Content[0], path, value, valueType)
 	if err != nil {
 		return err
 	}
 
-	json, err := yaml.Marshal(result)
+	output, err := m.render(documents[index], doc, edit)
 	if err != nil {
 		return err
 	}
-	err = m.Writer.WriteString(fileSpec, string(json))
+
+	documents[index] = m.retainMarker(documents[index], output)
+	err = m.Writer.WriteString(fileSpec, strings.Join(documents, ""))
 	return err
 }
+
+func (m YamlManipulator) DeleteValue(fileSpec string, valueSpec string) error {
+	content, err := m.Reader.ReadString(fileSpec)
+	if err != nil {
+		return err
+	}
+
+	documents := m.splitDocuments(content)
+	index, path, err := m.selectDocument(manipulators.SplitPath(valueSpec), len(documents))
+	if err != nil {
+		return err
+	}
+
+	// A path with only a document index deletes the entire document
+	if len(path) == 0 {
+		documents = append(documents[:index], documents[index+1:]...)
+		if len(documents) != 0 {
+			documents[0] = strings.TrimPrefix(documents[0], m.markerLine(documents[0]))
+		}
+		return m.Writer.WriteString(fileSpec, strings.Join(documents, ""))
+	}
+
+	doc, err := m.parseDocument(documents[index])
+	if err != nil {
+		return err
+	}
+
+	err = m.deleteNode(doc.Content[0], path)
+	if err != nil {
+		return err
+	}
+
+	output, err := m.render(documents[index], doc, nil)
+	if err != nil {
+		return err
+	}
+
+	documents[index] = m.retainMarker(documents[index], output)
+	err = m.Writer.WriteString(fileSpec, strings.Join(documents, ""))
+	return err
+}
+
+// splitDocuments splits a stream of YAML documents into the text of each document. Every document after the first
+// starts with a "---" marker. Comments and directives before the first marker are part of the first document.
+func (m YamlManipulator) splitDocuments(content string) []string {
+	documents := []string{}
+	start := 0
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		// The text before the first marker is only a document if it has content other than comments
+		if offset != 0 && m.markerLine(line) != "" && (len(documents) != 0 || m.hasContent(content[start:offset])) {
+			documents = append(documents, content[start:offset])
+			start = offset
+		}
+		offset += len(line)
+	}
+
+	return append(documents, content[start:])
+}
+
+// selectDocument returns the index of the document addressed by the first path element, along with the rest of the
+// path. Paths that do not start with a document index like "#1" address the first document.
+func (m YamlManipulator) selectDocument(path []string, count int) (int, []string, error) {
+	if len(path) == 0 || !documentPattern.MatchString(path[0]) {
+		return 0, path, nil
+	}
+
+	index, _, err := manipulators.ResolveArrayIndex(path[0][1:], count)
+	if err != nil {
+		return 0, nil, errors.New("the document index " + path[0] + " must select one of the " + fmt.Sprint(count) + " documents in the file")
+	}
+
+	return index, path[1:], nil
+}
+
+// retainMarker adds the "---" marker back to a document that was encoded rather than spliced, as the encoder does
+// not write the marker. The marker is placed after any comments at the start of the document.
+func (m YamlManipulator) retainMarker(original string, output string) string {
+	if !m.hasMarker(original) || m.hasMarker(output) {
+		return output
+	}
+
+	lines := strings.SplitAfter(output, "\n")
+	for i, line := range lines {
+		if !m.isComment(line) {
+			return strings.Join(lines[:i], "") + "---\n" + strings.Join(lines[i:], "")
+		}
+	}
+
+	return output + "---\n"
+}
+
+// hasMarker returns true if the document starts with a "---" marker, ignoring any comments before the marker.
+func (m YamlManipulator) hasMarker(document string) bool {
+	for _, line := range strings.SplitAfter(document, "\n") {
+		if m.markerLine(line) != "" {
+			return true
+		}
+		if !m.isComment(line) {
+			return false
+		}
+	}
+
+	return false
+}
+
+// isComment returns true if the line is blank, a comment, or a directive like "%YAML 1.2".
+func (m YamlManipulator) isComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(line, "%")
+}
+
+// markerLine returns the first line of the text if it is a "---" document marker, or an empty string otherwise.
+func (m YamlManipulator) markerLine(text string) string {
+	line := text
+	if index := strings.Index(text, "\n"); index != -1 {
+		line = text[:index+1]
+	}
+
+	if strings.HasPrefix(line, "---") && (len(line) == 3 || strings.ContainsRune(" \t\r\n", rune(line[3]))) {
+		return line
+	}
+
+	return ""
+}
+
+// hasContent returns true if the text contains a YAML value rather than just comments and directives.
+func (m YamlManipulator) hasContent(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if !m.isComment(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseDocument parses the content into a document node whose root is a mapping. Empty files are treated as an
+// empty object.
+func (m YamlManipulator) parseDocument(content string) (*yaml.Node, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal([]byte(content), &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || m.resolveAlias(doc.Content[0]).Kind != yaml.MappingNode {
+		return nil, errors.New("the root of the YAML document must be an object")
+	}
+
+	return &doc, nil
+}
+
+// render returns the content of the modified document. Where the only change was to replace a scalar with another
+// scalar, the new value is spliced into the original content. Otherwise, the document is encoded with the
+// indentation of the original file.
+func (m YamlManipulator) render(content string, doc *yaml.Node, edit *scalarEdit) (string, error) {
+	var expected any
+	err := doc.Decode(&expected)
+	if err != nil {
+		return "", err
+	}
+
+	if edit != nil {
+		spliced, ok := m.spliceScalar(content, *edit)
+		if ok {
+			// Only accept the spliced content if it is parsed to the same values as the modified document
+			var actual any
+			err = yaml.Unmarshal([]byte(spliced), &actual)
+			if err == nil && reflect.DeepEqual(expected, actual) {
+				return spliced, nil
+			}
+		}
+	}
+
+	m.clearMergeTags(doc)
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(m.detectIndent(content))
+	err = encoder.Encode(doc)
+	if err != nil {
+		return "", err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// clearMergeTags removes the tag from merge keys, which would otherwise be written as "!!merge <<".
+func (m YamlManipulator) clearMergeTags(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Tag == "!!merge" {
+				node.Content[j].Tag = ""
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		m.clearMergeTags(child)
+	}
+}
+
+// detectIndent returns the number of spaces used by the first indented line, defaulting to 2.
+func (m YamlManipulator) detectIndent(content string) int {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if indent > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return indent
+		}
+	}
+
+	return 2
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"gopkg.in/yaml.v3"
+	"strings"
 	"testing"
 )
 
@@ -751,7 +752,7 @@ func TestYamlSetArrayFieldIndexOutOfBounds(t *testing.T) {
 	}
 }
 
-func TestYamlSetArrayFieldAgainstObject(t *testing.T) {
+func TestYamlSetNumericKey(t *testing.T) {
 	yamlExample := "{\"whatever\":{\"hi\":\"there\"}}"
 	writer := writers.StringWriter{}
 	reader := readers.StringReader{
@@ -773,8 +774,42 @@ func TestYamlSetArrayFieldAgainstObject(t *testing.T) {
 
 	err := manipulator.SetValue("/etc/config.yaml", "whatever:10", "there")
 
+	if err != nil {
+		t.Fatal("Numbers must be treated as keys when used against an object: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	if err != nil {
+		t.Fatal("Failed to parse YAML file: " + err.Error())
+	}
+
+	if result["whatever"].(map[string]any)["10"] != "there" {
+		t.Fatal("The key \"10\" must be set to \"there\"")
+	}
+}
+
+func TestYamlSetQuotedKeyAgainstArray(t *testing.T) {
+	example := "{\"whatever\": [\"there\"]}"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": example,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "whatever:\"0\"", "there")
+
 	if err == nil {
-		t.Fatal("This should have failed")
+		t.Fatal("Quoted keys must not be used against an array")
 	}
 }
 
@@ -1291,3 +1326,933 @@ func TestYamlSetMissingNestedField(t *testing.T) {
 		t.Fatal("Should have failed to perform replacement")
 	}
 }
+
+func TestYamlSetNewNestedField(t *testing.T) {
+	yamlExample := "whatever: \"value\""
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "db:primary:host", "newvalue")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	db, ok := result["db"].(map[string]any)
+
+	if !ok {
+		t.Fatal("db must be an object")
+	}
+
+	primary, ok := db["primary"].(map[string]any)
+
+	if !ok {
+		t.Fatal("primary must be an object")
+	}
+
+	value, ok := primary["host"].(string)
+
+	if !ok {
+		t.Fatal("Value must be a string")
+	}
+
+	if value != "newvalue" {
+		t.Fatal("New value must be set to \"newvalue\" (was: \"" + fmt.Sprint(value) + "\"")
+	}
+
+	if result["whatever"] != "value" {
+		t.Fatal("Existing values must be retained")
+	}
+}
+
+func TestYamlSetNestedArrayIndex(t *testing.T) {
+	yamlExample := "servers:\n- port: 80\n- port: 81"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "servers:1:port", "8080")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	servers, ok := result["servers"].([]any)
+
+	if !ok || len(servers) != 2 {
+		t.Fatal("servers must be an array with two items")
+	}
+
+	server, ok := servers[1].(map[string]any)
+
+	if !ok {
+		t.Fatal("server must be an object")
+	}
+
+	if fmt.Sprint(server["port"]) != "8080" {
+		t.Fatal("Value must be set to \"8080\" (was: \"" + fmt.Sprint(server["port"]) + "\"")
+	}
+
+	server, ok = servers[0].(map[string]any)
+
+	if !ok || fmt.Sprint(server["port"]) != "80" {
+		t.Fatal("Other array items must be retained")
+	}
+}
+
+func TestYamlSetNestedArrayIndexOutOfBounds(t *testing.T) {
+	yamlExample := "servers:\n- port: 80\n- port: 81"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "servers:5:port", "8080")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+
+	if !strings.Contains(err.Error(), "\"5\"") {
+		t.Fatal("The error must identify the path element (was: \"" + err.Error() + "\"")
+	}
+}
+
+func TestYamlSetArrayAppend(t *testing.T) {
+	yamlExample := "brokers:\n- a\n- b\nports:\n- 80"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "brokers:-", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a b c]" {
+		t.Fatal("Value must be set to \"[a b c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestYamlSetArrayInsert(t *testing.T) {
+	yamlExample := "brokers:\n- a\n- b\nports:\n- 80"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "brokers:+1", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c b]" {
+		t.Fatal("Value must be set to \"[a c b]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestYamlSetArrayNegativeIndex(t *testing.T) {
+	yamlExample := "brokers:\n- a\n- b\nports:\n- 80"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "brokers:-1", "c")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c]" {
+		t.Fatal("Value must be set to \"[a c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestYamlSetArrayNegativeIndexOutOfBounds(t *testing.T) {
+	yamlExample := "brokers:\n- a\n- b\nports:\n- 80"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "brokers:-3", "c")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+}
+
+func TestYamlSetArrayAppendNewArray(t *testing.T) {
+	yamlExample := "brokers:\n- a\n- b\nports:\n- 80"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "servers:-:host", "localhost")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	servers, ok := result["servers"].([]any)
+
+	if !ok || len(servers) != 1 {
+		t.Fatal("servers must be an array with one item")
+	}
+
+	server, ok := servers[0].(map[string]any)
+
+	if !ok || server["host"] != "localhost" {
+		t.Fatal("The new item must be an object with the host set to \"localhost\" (was: \"" + fmt.Sprint(servers[0]) + "\"")
+	}
+}
+
+func TestYamlSetArrayAppendRetainsType(t *testing.T) {
+	yamlExample := "brokers:\n- a\n- b\nports:\n- 80"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "ports:-", "81")
+
+	if err != nil {
+		t.Fatal("Failed to perform replacement: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	ports, ok := result["ports"].([]any)
+
+	if !ok || len(ports) != 2 {
+		t.Fatal("ports must be an array with two items")
+	}
+
+	if _, ok := ports[1].(string); ok {
+		t.Fatal("The new item must retain the number type of its siblings")
+	}
+}
+
+func TestYamlDeleteNestedField(t *testing.T) {
+	yamlExample := "whatever: value\ndb:\n  host: localhost\n  port: 5432\nbrokers:\n- a\n- b\n- c"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.yaml", "db:host")
+
+	if err != nil {
+		t.Fatal("Failed to delete value: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	db, ok := result["db"].(map[string]any)
+
+	if !ok {
+		t.Fatal("db must be an object")
+	}
+
+	if _, ok := db["host"]; ok {
+		t.Fatal("host must have been deleted")
+	}
+
+	if _, ok := db["port"]; !ok {
+		t.Fatal("port must have been retained")
+	}
+}
+
+func TestYamlDeleteArrayItem(t *testing.T) {
+	yamlExample := "whatever: value\ndb:\n  host: localhost\n  port: 5432\nbrokers:\n- a\n- b\n- c"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.yaml", "brokers:1")
+
+	if err != nil {
+		t.Fatal("Failed to delete value: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	if fmt.Sprint(result["brokers"]) != "[a c]" {
+		t.Fatal("brokers must be set to \"[a c]\" (was: \"" + fmt.Sprint(result["brokers"]) + "\"")
+	}
+}
+
+func TestYamlDeleteMissingField(t *testing.T) {
+	yamlExample := "whatever: value\ndb:\n  host: localhost\n  port: 5432\nbrokers:\n- a\n- b\n- c"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.yaml", "missing:host")
+
+	if err != nil {
+		t.Fatal("Deleting a missing value must not fail: " + err.Error())
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte((*writer.Output)["/etc/config.yaml"]), &result)
+
+	if result["whatever"] != "value" {
+		t.Fatal("Existing values must be retained")
+	}
+}
+
+func TestYamlDeleteAppend(t *testing.T) {
+	yamlExample := "whatever: value\ndb:\n  host: localhost\n  port: 5432\nbrokers:\n- a\n- b\n- c"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/config.yaml") {
+		t.Fatal("Must be able to manipulate YAML files")
+	}
+
+	err := manipulator.DeleteValue("/etc/config.yaml", "brokers:-")
+
+	if err == nil {
+		t.Fatal("This should have failed")
+	}
+}
+
+const commentedYaml = `# Defaults for the service
+server:
+  # The address to listen on
+  host: "0.0.0.0"   # all interfaces
+  port: 8080
+  tls: false
+defaults: &defaults
+  timeout: 30s
+  retries: 3
+primary:
+  <<: *defaults
+  name: 'primary db'
+zones: [eu-west, us-east]
+`
+
+func TestYamlSetPreservesFormatting(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": commentedYaml,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	tests := map[string][]string{
+		"server:host":      {"127.0.0.1", "  host: \"0.0.0.0\"   # all interfaces", "  host: \"127.0.0.1\"   # all interfaces"},
+		"server:port":      {"9090", "  port: 8080", "  port: 9090"},
+		"server:tls":       {"true", "  tls: false", "  tls: true"},
+		"defaults:retries": {"5", "  retries: 3", "  retries: 5"},
+		"primary:name":     {"it's primary", "  name: 'primary db'", "  name: 'it''s primary'"},
+		"zones:1":          {"ap-south", "zones: [eu-west, us-east]", "zones: [eu-west, ap-south]"},
+	}
+
+	for accessor, test := range tests {
+		err := manipulator.SetValue("/etc/config.yaml", accessor, test[0])
+
+		if err != nil {
+			t.Fatal("Failed to manipulate YAML file: " + err.Error())
+		}
+
+		expected := strings.Replace(commentedYaml, test[1], test[2], 1)
+		output := (*writer.Output)["/etc/config.yaml"]
+
+		if output != expected {
+			t.Fatal("Only the value of " + accessor + " must be changed (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestYamlSetAnchoredValue(t *testing.T) {
+	yamlExample := "base: &timeout 30\nclient:\n  timeout: *timeout\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "base", "60")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate YAML file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/config.yaml"]
+
+	if output != "base: &timeout 60\nclient:\n  timeout: *timeout\n" {
+		t.Fatal("The anchor and alias must be retained (was: \"" + output + "\"")
+	}
+}
+
+func TestYamlSetNewFieldPreservesComments(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": commentedYaml,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "server:logging:level", "debug")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate YAML file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/config.yaml"]
+
+	for _, expected := range []string{"# Defaults for the service", "# The address to listen on", "# all interfaces", "&defaults", "*defaults"} {
+		if !strings.Contains(output, expected) {
+			t.Fatal("Output must contain \"" + expected + "\" (was: \"" + output + "\"")
+		}
+	}
+
+	if strings.Index(output, "server:") > strings.Index(output, "defaults:") || strings.Index(output, "primary:") > strings.Index(output, "zones:") {
+		t.Fatal("Key order must be retained (was: \"" + output + "\"")
+	}
+
+	var result map[string]any
+	err = yaml.Unmarshal([]byte(output), &result)
+
+	if err != nil {
+		t.Fatal("Output must be valid YAML: " + err.Error())
+	}
+
+	level := result["server"].(map[string]any)["logging"].(map[string]any)["level"]
+
+	if level != "debug" {
+		t.Fatal("Value must be set to \"debug\" (was: \"" + fmt.Sprint(level) + "\"")
+	}
+}
+
+func TestYamlDeletePreservesComments(t *testing.T) {
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": commentedYaml,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	err := manipulator.DeleteValue("/etc/config.yaml", "server:tls")
+
+	if err != nil {
+		t.Fatal("Failed to delete value: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/config.yaml"]
+
+	if strings.Contains(output, "tls") {
+		t.Fatal("tls must have been deleted (was: \"" + output + "\"")
+	}
+
+	if !strings.Contains(output, "# The address to listen on") || !strings.Contains(output, "*defaults") {
+		t.Fatal("Comments and aliases must be retained (was: \"" + output + "\"")
+	}
+}
+
+func TestYamlSetRetainsIntegers(t *testing.T) {
+	yamlExample := "port: 8080\nratio: 1.5\nid: 9007199254740993\nports: [80]\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"port", "9090", strings.Replace(yamlExample, "8080", "9090", 1)},
+		{"ratio", "2", strings.Replace(yamlExample, "1.5", "2.0", 1)},
+		{"id", "9007199254740995", strings.Replace(yamlExample, "9007199254740993", "9007199254740995", 1)},
+		{"ports:-", "443", strings.Replace(yamlExample, "[80]", "[80, 443]", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/config.yaml", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate YAML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.yaml"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must retain the number type (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestYamlSetTypedValue(t *testing.T) {
+	yamlExample := "code: abc\nenabled: \"yes\"\nport: 8080\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor  string
+		value     string
+		valueType manipulators.ValueType
+		expected  string
+	}{
+		{"code", "0012", manipulators.ValueTypeString, strings.Replace(yamlExample, "abc", "\"0012\"", 1)},
+		{"code", "12", manipulators.ValueTypeInt, strings.Replace(yamlExample, "abc", "12", 1)},
+		{"enabled", "true", manipulators.ValueTypeBool, strings.Replace(yamlExample, "\"yes\"", "true", 1)},
+		{"port", "8080", manipulators.ValueTypeString, strings.Replace(yamlExample, "8080", "\"8080\"", 1)},
+		{"port", "2", manipulators.ValueTypeFloat, strings.Replace(yamlExample, "8080", "2.0", 1)},
+		{"port", "", manipulators.ValueTypeNull, strings.Replace(yamlExample, "8080", "null", 1)},
+		{"timeout", "30", manipulators.ValueTypeInt, yamlExample + "timeout: 30\n"},
+		{"ports", "[80, 443]", manipulators.ValueTypeJson, yamlExample + "ports:\n  - 80\n  - 443\n"},
+	}
+
+	for _, test := range tests {
+		(*reader.Files)["/etc/config.yaml"] = yamlExample
+		err := manipulator.SetTypedValue("/etc/config.yaml", test.accessor, test.value, test.valueType)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate YAML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.yaml"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " as " + test.valueType.String() + " must write the hinted type (was: \"" + output + "\"")
+		}
+	}
+
+	err := manipulator.SetTypedValue("/etc/config.yaml", "port", "abc", manipulators.ValueTypeInt)
+
+	if err == nil {
+		t.Fatal("Setting an invalid int must fail")
+	}
+}
+
+func TestYamlSetEscapedPath(t *testing.T) {
+	yamlExample := "logging:\n  level:root: info\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	err := manipulator.SetValue("/etc/config.yaml", "logging:level\\:root", "debug")
+
+	if err != nil {
+		t.Fatal("Failed to manipulate YAML file: " + err.Error())
+	}
+
+	output := (*writer.Output)["/etc/config.yaml"]
+
+	if output != "logging:\n  level:root: debug\n" {
+		t.Fatal("Escaped colons must be part of the key (was: \"" + output + "\"")
+	}
+}
+
+func TestYamlSetNumericKeys(t *testing.T) {
+	yamlExample := "ports:\n  8080: http\n  \"8443\": https\nstatus: [ok]\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/config.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"ports:8080", "web", strings.Replace(yamlExample, "8080: http", "8080: web", 1)},
+		{"ports:\"8443\"", "tls", strings.Replace(yamlExample, "https", "tls", 1)},
+		{"status:0", "failed", strings.Replace(yamlExample, "[ok]", "[failed]", 1)},
+	}
+
+	for _, test := range tests {
+		err := manipulator.SetValue("/etc/config.yaml", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate YAML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/config.yaml"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must modify the matching key or index (was: \"" + output + "\"")
+		}
+	}
+}
+
+func TestYamlMultipleDocuments(t *testing.T) {
+	yamlExample := "# Deployment\n" +
+		"---\n" +
+		"apiVersion: apps/v1\n" +
+		"kind: Deployment\n" +
+		"spec:\n" +
+		"  replicas: 1 # scaled by the operator\n" +
+		"---\n" +
+		"apiVersion: v1\n" +
+		"kind: Service\n" +
+		"spec:\n" +
+		"  ports:\n" +
+		"    - port: 80\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/manifests.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	if !manipulator.CanManipulate("/etc/manifests.yaml") {
+		t.Fatal("Must be able to manipulate YAML files with many documents")
+	}
+
+	tests := []struct {
+		accessor string
+		value    string
+		expected string
+	}{
+		{"spec:replicas", "3", strings.Replace(yamlExample, "replicas: 1", "replicas: 3", 1)},
+		{"#0:spec:replicas", "3", strings.Replace(yamlExample, "replicas: 1", "replicas: 3", 1)},
+		{"#1:spec:ports:0:port", "8080", strings.Replace(yamlExample, "port: 80", "port: 8080", 1)},
+		{"#-1:kind", "Ingress", strings.Replace(yamlExample, "kind: Service", "kind: Ingress", 1)},
+		{"#1:metadata:name", "web", yamlExample + "metadata:\n  name: web\n"},
+		{"#1:spec:ports:-", "{\"port\": 443}", strings.Replace(yamlExample, "    - port: 80\n", "    - port: 80\n    - port: 443\n", 1)},
+		{"\"#1\"", "value", strings.Replace(yamlExample, "# scaled by the operator\n", "# scaled by the operator\n'#1': value\n", 1)},
+	}
+
+	for _, test := range tests {
+		(*reader.Files)["/etc/manifests.yaml"] = yamlExample
+		err := manipulator.SetValue("/etc/manifests.yaml", test.accessor, test.value)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate YAML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/manifests.yaml"]
+
+		if output != test.expected {
+			t.Fatal("Setting " + test.accessor + " must only change the matching document (was: \"" + output + "\")")
+		}
+	}
+
+	for _, accessor := range []string{"#2:kind", "#1"} {
+		(*reader.Files)["/etc/manifests.yaml"] = yamlExample
+		err := manipulator.SetValue("/etc/manifests.yaml", accessor, "value")
+
+		if err == nil {
+			t.Fatal("Setting " + accessor + " must fail")
+		}
+	}
+}
+
+func TestYamlDeleteFromMultipleDocuments(t *testing.T) {
+	yamlExample := "kind: ConfigMap\ndata:\n  a: b\n---\nkind: Secret\ndata:\n  c: d\n---\nkind: Service\n"
+	writer := writers.StringWriter{}
+	reader := readers.StringReader{
+		Files: &map[string]string{
+			"/etc/manifests.yaml": yamlExample,
+		},
+	}
+	manipulator := YamlManipulator{
+		Writer: &writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: YamlUnmarshaller{},
+		},
+	}
+
+	tests := []struct {
+		accessor string
+		expected string
+	}{
+		{"#1:data:c", "kind: ConfigMap\ndata:\n  a: b\n---\nkind: Secret\ndata: {}\n---\nkind: Service\n"},
+		{"#1", "kind: ConfigMap\ndata:\n  a: b\n---\nkind: Service\n"},
+		{"#0", "kind: Secret\ndata:\n  c: d\n---\nkind: Service\n"},
+		{"#2", "kind: ConfigMap\ndata:\n  a: b\n---\nkind: Secret\ndata:\n  c: d\n"},
+	}
+
+	for _, test := range tests {
+		(*reader.Files)["/etc/manifests.yaml"] = yamlExample
+		err := manipulator.DeleteValue("/etc/manifests.yaml", test.accessor)
+
+		if err != nil {
+			t.Fatal("Failed to manipulate YAML file: " + err.Error())
+		}
+
+		output := (*writer.Output)["/etc/manifests.yaml"]
+
+		if output != test.expected {
+			t.Fatal("Deleting " + test.accessor + " must only change the matching document (was: \"" + output + "\")")
+		}
+	}
+}
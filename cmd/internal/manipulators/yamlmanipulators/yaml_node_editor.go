@@ -0,0 +1,282 @@
+package yamlmanipulators
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"gopkg.in/yaml.v3"
+	"strconv"
+)
+
+// scalarEdit records a scalar that was replaced with another scalar. These edits can be written back to the
+// original document without re-encoding the rest of the file.
+type scalarEdit struct {
+	original    yaml.Node
+	replacement *yaml.Node
+	flow        bool
+}
+
+// setNode navigates the path from the root node, creating any missing objects, and sets the value at the end of
+// the path. The same rules as CommonMapManipulator.ProcessMap apply, but comments, key order, and anchors are
+// retained.
+func (m YamlManipulator) setNode(root *yaml.Node, path []string, value string, valueType manipulators.ValueType) (*scalarEdit, error) {
+	current := root
+	flow := false
+	for i, p := range path {
+		last := i == len(path)-1
+		current = m.resolveAlias(current)
+		flow = flow || current.Style&yaml.FlowStyle != 0
+
+		// Numbers are array indexes, unless the current node is a mapping with numeric keys
+		if manipulators.IsArrayIndex(p) && current.Kind != yaml.MappingNode {
+			if current.Kind != yaml.SequenceNode {
+				return nil, errors.New("integer indexes must be used against an existing array (object type was " + m.getType(current) + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			index, insert, err := manipulators.ResolveArrayIndex(p, len(current.Content))
+			if err != nil {
+				return nil, errors.New(err.Error() + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements")
+			}
+
+			if insert {
+				var item *yaml.Node
+				if last {
+					// New items retain the type of their siblings
+					var sibling *yaml.Node
+					if len(current.Content) != 0 {
+						sibling = current.Content[m.min(index, len(current.Content)-1)]
+					}
+
+					item, err = m.convertValue(sibling, value, valueType)
+					if err != nil {
+						return nil, err
+					}
+				} else {
+					item = m.newContainer(path[i+1])
+				}
+
+				current.Content = append(current.Content[:index], append([]*yaml.Node{item}, current.Content[index:]...)...)
+				current = item
+				continue
+			}
+
+			if last {
+				return m.replaceValue(current.Content[index], value, valueType, flow)
+			}
+
+			current = current.Content[index]
+		} else {
+			if current.Kind != yaml.MappingNode {
+				return nil, errors.New("failed to navigate through YAML object to desired location (object type was " + m.getType(current) + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			key := manipulators.PathKey(p)
+			child := m.getMappingValue(current, key)
+
+			if last {
+				if child != nil {
+					return m.replaceValue(child, value, valueType, flow)
+				}
+
+				item, err := m.convertValue(nil, value, valueType)
+				if err != nil {
+					return nil, err
+				}
+
+				current.Content = append(current.Content, m.newKey(key), item)
+				return nil, nil
+			}
+
+			// Missing (or null) intermediate keys are created as empty objects, which allows
+			// new sections to be added to sparse config files
+			if child == nil {
+				child = m.newContainer(path[i+1])
+				current.Content = append(current.Content, m.newKey(key), child)
+			} else if m.resolveAlias(child).Tag == "!!null" {
+				m.replaceNode(child, m.newContainer(path[i+1]))
+			}
+
+			current = child
+		}
+	}
+
+	return nil, nil
+}
+
+// deleteNode removes the key or array item at the end of the path. Paths that do not exist are ignored.
+func (m YamlManipulator) deleteNode(root *yaml.Node, path []string) error {
+	current := root
+	for i, p := range path {
+		last := i == len(path)-1
+		current = m.resolveAlias(current)
+
+		if manipulators.IsArrayIndex(p) && current.Kind != yaml.MappingNode {
+			if manipulators.IsArrayInsert(p) {
+				return errors.New("append and insert indexes can not be used when deleting values (path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			if current.Kind != yaml.SequenceNode {
+				return errors.New("integer indexes must be used against an existing array (object type was " + m.getType(current) + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			index, _, err := manipulators.ResolveArrayIndex(p, len(current.Content))
+			if err != nil {
+				// There is no item to delete
+				return nil
+			}
+
+			if last {
+				current.Content = append(current.Content[:index:index], current.Content[index+1:]...)
+				return nil
+			}
+
+			current = current.Content[index]
+		} else {
+			if current.Kind != yaml.MappingNode {
+				return errors.New("failed to navigate through YAML object to desired location (object type was " + m.getType(current) + " at path element \"" + p + "\", element " + fmt.Sprint(i+1) + " in a path with " + fmt.Sprint(len(path)) + " elements)")
+			}
+
+			key := manipulators.PathKey(p)
+			if last {
+				for j := 0; j+1 < len(current.Content); j += 2 {
+					if current.Content[j].Value == key {
+						current.Content = append(current.Content[:j:j], current.Content[j+2:]...)
+						return nil
+					}
+				}
+				return nil
+			}
+
+			current = m.getMappingValue(current, key)
+			if current == nil {
+				// There is no key to delete
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// replaceValue converts the value to match the type hint or the type of the existing node, and replaces the node
+// in place.
+func (m YamlManipulator) replaceValue(existing *yaml.Node, value string, valueType manipulators.ValueType, flow bool) (*scalarEdit, error) {
+	replacement, err := m.convertValue(existing, value, valueType)
+	if err != nil {
+		return nil, err
+	}
+
+	original := *existing
+	m.replaceNode(existing, replacement)
+
+	if original.Kind == yaml.ScalarNode && replacement.Kind == yaml.ScalarNode {
+		return &scalarEdit{
+			original:    original,
+			replacement: existing,
+			flow:        flow,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// convertValue builds a new node from the value, retaining the type of the existing node where possible if there
+// is no type hint.
+func (m YamlManipulator) convertValue(existing *yaml.Node, value string, valueType manipulators.ValueType) (*yaml.Node, error) {
+	var existingValue any
+	if existing != nil {
+		err := m.resolveAlias(existing).Decode(&existingValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	converted, err := m.MapManipulator.ConvertTypedValue(existingValue, value, valueType)
+	if err != nil {
+		return nil, err
+	}
+
+	node := yaml.Node{}
+	err = node.Encode(converted)
+	if err != nil {
+		return nil, err
+	}
+
+	// Whole floats are encoded like integers, so add a decimal point to retain the float type
+	if _, ok := converted.(float64); ok && node.Kind == yaml.ScalarNode {
+		if _, err := strconv.ParseInt(node.Value, 10, 64); err == nil {
+			node.Value += ".0"
+			node.Tag = "!!float"
+		}
+	}
+
+	// Retain the quoting style of strings
+	if existing != nil && existing.Kind == yaml.ScalarNode && existing.Tag == "!!str" && node.Tag == "!!str" && existing.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		node.Style = existing.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+	}
+
+	return &node, nil
+}
+
+// replaceNode overwrites the existing node in place, so any aliases referencing it see the new value, and
+// retains the existing anchor and comments.
+func (m YamlManipulator) replaceNode(existing *yaml.Node, replacement *yaml.Node) {
+	replacement.Anchor = existing.Anchor
+	replacement.HeadComment = existing.HeadComment
+	replacement.LineComment = existing.LineComment
+	replacement.FootComment = existing.FootComment
+	replacement.Line = existing.Line
+	replacement.Column = existing.Column
+	*existing = *replacement
+}
+
+func (m YamlManipulator) getMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for j := 0; j+1 < len(mapping.Content); j += 2 {
+		if mapping.Content[j].Value == key {
+			return mapping.Content[j+1]
+		}
+	}
+
+	return nil
+}
+
+func (m YamlManipulator) resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	return node
+}
+
+// newContainer creates an empty sequence if the next path element adds an item to an array, or an empty mapping
+// otherwise.
+func (m YamlManipulator) newContainer(nextElement string) *yaml.Node {
+	if manipulators.IsArrayInsert(nextElement) {
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func (m YamlManipulator) newKey(key string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+}
+
+func (m YamlManipulator) getType(node *yaml.Node) string {
+	switch m.resolveAlias(node).Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	default:
+		return "scalar"
+	}
+}
+
+func (m YamlManipulator) min(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
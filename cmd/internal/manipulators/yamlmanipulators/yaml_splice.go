@@ -0,0 +1,130 @@
+package yamlmanipulators
+
+import (
+	"gopkg.in/yaml.v3"
+	"strings"
+	"unicode"
+)
+
+// spliceScalar replaces the text of the original scalar with the text of its replacement, leaving the rest of the
+// content untouched. The returned boolean is false if the scalar could not be located in the content, in which
+// case the document must be encoded instead.
+func (m YamlManipulator) spliceScalar(content string, edit scalarEdit) (string, bool) {
+	original := edit.original
+
+	// Block scalars span many lines, and explicit tags are not reproduced, so these are left to the encoder
+	if original.Style&(yaml.LiteralStyle|yaml.FoldedStyle|yaml.TaggedStyle) != 0 {
+		return "", false
+	}
+
+	replacement, ok := m.scalarText(*edit.replacement)
+	if !ok {
+		return "", false
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	if original.Line < 1 || original.Line > len(lines) {
+		return "", false
+	}
+
+	// Node columns count characters rather than bytes
+	line := []rune(lines[original.Line-1])
+	start := original.Column - 1
+	if start < 0 || start > len(line) {
+		return "", false
+	}
+
+	// The column of an anchored node points to the anchor rather than the value
+	if original.Anchor != "" {
+		anchor := []rune("&" + original.Anchor)
+		if !strings.HasPrefix(string(line[start:]), string(anchor)) {
+			return "", false
+		}
+		start += len(anchor)
+		for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+			start++
+		}
+	}
+
+	end, ok := m.scalarEnd(line, start, original.Style, edit.flow)
+	if !ok {
+		return "", false
+	}
+
+	lines[original.Line-1] = string(line[:start]) + replacement + string(line[end:])
+	return strings.Join(lines, ""), true
+}
+
+// scalarText encodes a scalar node as it would appear in a document. Scalars that can not be written on a
+// single line are rejected.
+func (m YamlManipulator) scalarText(node yaml.Node) (string, bool) {
+	node.Anchor = ""
+	node.HeadComment = ""
+	node.LineComment = ""
+	node.FootComment = ""
+
+	text, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", false
+	}
+
+	result := strings.TrimSuffix(string(text), "\n")
+	if result == "" || strings.Contains(result, "\n") {
+		return "", false
+	}
+
+	return result, true
+}
+
+// scalarEnd returns the index of the character after the scalar that starts at the supplied index.
+func (m YamlManipulator) scalarEnd(line []rune, start int, style yaml.Style, flow bool) (int, bool) {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		if start >= len(line) || line[start] != '"' {
+			return 0, false
+		}
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '"' {
+				return i + 1, true
+			}
+		}
+		return 0, false
+	case style&yaml.SingleQuotedStyle != 0:
+		if start >= len(line) || line[start] != '\'' {
+			return 0, false
+		}
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+				} else {
+					return i + 1, true
+				}
+			}
+		}
+		return 0, false
+	default:
+		// Plain scalars end at a comment, the end of the line, or a flow collection delimiter
+		end := len(line)
+		for i := start; i < len(line); i++ {
+			if line[i] == '\n' || line[i] == '\r' {
+				end = i
+				break
+			}
+			if line[i] == '#' && i > start && unicode.IsSpace(line[i-1]) {
+				end = i
+				break
+			}
+			if flow && (line[i] == ',' || line[i] == ']' || line[i] == '}') {
+				end = i
+				break
+			}
+		}
+		for end > start && unicode.IsSpace(line[end-1]) {
+			end--
+		}
+		return end, end > start
+	}
+}
@@ -1,7 +1,7 @@
 package readers
 
 import (
-	"errors"
+	"io/fs"
 )
 
 type StringReader struct {
@@ -15,5 +15,5 @@ func (r StringReader) ReadString(fileSpec string) (string, error) {
 		return file, nil
 	}
 
-	return "", errors.New("file " + file + " was not defined")
+	return "", &fs.PathError{Op: "open", Path: fileSpec, Err: fs.ErrNotExist}
 }
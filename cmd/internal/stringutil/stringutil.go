@@ -1,5 +1,10 @@
 package stringutil
 
+import (
+	"errors"
+	"strings"
+)
+
 func Substr(input string, start int, length int) string {
 	asRunes := []rune(input)
 
@@ -13,3 +18,86 @@ func Substr(input string, start int, length int) string {
 
 	return string(asRunes[start : start+length])
 }
+
+// BracketedSegments extracts count bracketed segments from the start of the input, returning the contents of
+// each segment and any remaining text. Brackets nested inside a segment must be balanced, which allows
+// accessors like XPath filters e.g. "[/web.config][/configuration/add[@key='Db']/@value]newvalue". Brackets
+// escaped with a backslash are ignored, and the escape sequences are retained in the returned segments.
+func BracketedSegments(input string, count int) ([]string, string, error) {
+	segments := []string{}
+	remaining := input
+
+	for len(segments) < count {
+		if len(remaining) == 0 || remaining[0] != '[' {
+			return nil, "", errors.New("expected a bracketed segment at the start of \"" + remaining + "\"")
+		}
+
+		depth := 0
+		end := -1
+		escaped := false
+		for i, c := range remaining {
+			if escaped {
+				escaped = false
+			} else if c == '\\' && i+1 < len(remaining) && IsEscapable(remaining[i+1]) {
+				escaped = true
+			} else if c == '[' {
+				depth++
+			} else if c == ']' {
+				depth--
+				if depth == 0 {
+					end = i
+					break
+				}
+			}
+		}
+
+		if end < 0 {
+			return nil, "", errors.New("unbalanced brackets in \"" + remaining + "\"")
+		}
+
+		if end == 1 {
+			return nil, "", errors.New("bracketed segments can not be empty")
+		}
+
+		segments = append(segments, remaining[1:end])
+		remaining = remaining[end+1:]
+	}
+
+	return segments, remaining, nil
+}
+
+// Unescape replaces the escape sequences "\\:", "\\[", "\\]", and "\\\\" with the escaped character. Any other
+// backslash is retained, so paths like "C:\\app\\config.json" do not need to be escaped.
+func Unescape(input string) string {
+	if !strings.Contains(input, "\\") {
+		return input
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] == '\\' && i+1 < len(input) && IsEscapable(input[i+1]) {
+			i++
+		}
+		builder.WriteByte(input[i])
+	}
+
+	return builder.String()
+}
+
+// IsEscapable returns true if the character can be escaped with a backslash.
+func IsEscapable(c byte) bool {
+	return c == ':' || c == '[' || c == ']' || c == '\\'
+}
+
+// Escape adds a backslash before any character that can be escaped, reversing Unescape.
+func Escape(input string) string {
+	var builder strings.Builder
+	for i := 0; i < len(input); i++ {
+		if IsEscapable(input[i]) {
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(input[i])
+	}
+
+	return builder.String()
+}
@@ -8,15 +8,23 @@ import (
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/envscanners"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/executors"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/dotenvmanipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/hclmanipulators"
 	inimanipulators "github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/inimanipulator"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/jsonmanipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/propertiesmanipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/tomlmanipulators"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/xmlmanipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/manipulators/yamlmanipulators"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/readers"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/writers"
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"io/fs"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func setLogging() {
@@ -49,6 +57,14 @@ func doScanning() error {
 		},
 	}
 
+	json5Manipulator := jsonmanipulators.Json5Manipulator{
+		Writer: writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: jsonmanipulators.Json5Unmarshaller{},
+		},
+	}
+
 	yamlManipulator := yamlmanipulators.YamlManipulator{
 		Writer: writer,
 		Reader: reader,
@@ -65,6 +81,43 @@ func doScanning() error {
 		},
 	}
 
+	xmlManipulator := xmlmanipulators.XmlManipulator{
+		Writer: writer,
+		Reader: reader,
+	}
+
+	propertiesManipulator := propertiesmanipulators.PropertiesManipulator{
+		Writer: writer,
+		Reader: reader,
+	}
+
+	dotenvManipulator := dotenvmanipulators.DotenvManipulator{
+		Writer: writer,
+		Reader: reader,
+	}
+
+	hclManipulator := hclmanipulators.HclManipulator{
+		Writer: writer,
+		Reader: reader,
+		MapManipulator: manipulators.CommonMapManipulator{
+			Unmarshaller: hclmanipulators.HclUnmarshaller{},
+		},
+	}
+
+	// Files with names that don't match any format are assigned the first format that can parse them,
+	// so the strictest formats are listed first
+	manipulatorList := []manipulators.Manipulator{
+		jsonManipulator,
+		json5Manipulator,
+		xmlManipulator,
+		yamlManipulator,
+		tomlManipulator,
+		iniManipulator,
+		hclManipulator,
+		dotenvManipulator,
+		propertiesManipulator,
+	}
+
 	scanners := []envscanners.EnvScanner{
 
 		envscanners.FileWriterEnvScanner{
@@ -87,61 +140,149 @@ func doScanning() error {
 			Env:    envprovider,
 		},
 
+		envscanners.ReplaceEnvScanner{
+			Env:    envprovider,
+			Reader: reader,
+			Writer: writer,
+		},
+
+		envscanners.ReplaceEnvScannerTwo{
+			Env:    envprovider,
+			Reader: reader,
+			Writer: writer,
+		},
+
 		envscanners.ManipulatorEnvScanner{
-			Env: envprovider,
-			Manipulator: []manipulators.Manipulator{
-				iniManipulator,
-				jsonManipulator,
-				yamlManipulator,
-				tomlManipulator,
-			},
+			Env:         envprovider,
+			Manipulator: manipulatorList,
 		},
 
 		envscanners.ManipulatorEnvScannerTwo{
-			Env: envprovider,
-			Manipulator: []manipulators.Manipulator{
-				iniManipulator,
-				jsonManipulator,
-				yamlManipulator,
-				tomlManipulator,
-			},
+			Env:         envprovider,
+			Manipulator: manipulatorList,
 		},
 
 		envscanners.ManipulatorSkipEmptyEnvScanner{
-			Env: envprovider,
-			Manipulator: []manipulators.Manipulator{
-				iniManipulator,
-				jsonManipulator,
-				yamlManipulator,
-				tomlManipulator,
-			},
+			Env:         envprovider,
+			Manipulator: manipulatorList,
 		},
 
 		envscanners.ManipulatorSkipEmptyEnvScannerTwo{
-			Env: envprovider,
-			Manipulator: []manipulators.Manipulator{
-				iniManipulator,
-				jsonManipulator,
-				yamlManipulator,
-				tomlManipulator,
-			},
+			Env:         envprovider,
+			Manipulator: manipulatorList,
+		},
+
+		envscanners.ManipulatorDeleteEnvScanner{
+			Env:         envprovider,
+			Manipulator: manipulatorList,
+		},
+
+		envscanners.ManipulatorDeleteEnvScannerTwo{
+			Env:         envprovider,
+			Manipulator: manipulatorList,
 		},
 	}
 
+	// Env vars that could not be applied are collected from every scanner, so they can all be reported at once
+	unapplied := []*customerror.UdlError{}
+
 	for _, scanner := range scanners {
 		err := scanner.ProcessEnvVars()
 
+		var unappliedError *customerror.UnappliedError
+		if errors.As(err, &unappliedError) {
+			unapplied = append(unapplied, unappliedError.Errors...)
+			continue
+		}
+
 		if err != nil {
 			return err
 		}
 	}
 
+	if len(unapplied) == 0 {
+		return nil
+	}
+
+	// In strict mode, the failures are reported by main when it exits
+	if isStrict() {
+		return &customerror.UnappliedError{Errors: unapplied}
+	}
+
+	for _, err := range unapplied {
+		log.Error().Msg("Environment variable \"" + err.EnvVar + "\" was not applied: " + err.Error())
+	}
+
 	return nil
 }
 
+// isStrict returns true unless UDL_STRICT is set to false. In strict mode, UDL exits with an error if any
+// environment variable could not be applied.
+func isStrict() bool {
+	return getBoolEnv("UDL_STRICT", true)
+}
+
+// getBoolEnv returns the boolean value of an env var, or the default value if the env var is not set or is not
+// a boolean.
+func getBoolEnv(name string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getStopSignal returns the signal set with UDL_STOP_SIGNAL, or nil to forward the signals sent to UDL as is.
+func getStopSignal() os.Signal {
+	name := os.Getenv("UDL_STOP_SIGNAL")
+	if name == "" {
+		return nil
+	}
+
+	stopSignal, err := executors.ParseSignal(name)
+	if err != nil {
+		log.Fatal().Msg("UDL_STOP_SIGNAL is invalid: " + err.Error())
+	}
+	return stopSignal
+}
+
+// getStopTimeout returns the duration set with UDL_STOP_TIMEOUT, like "25s" or a number of seconds like "25", or 0
+// to wait for the application to stop forever.
+func getStopTimeout() time.Duration {
+	value := os.Getenv("UDL_STOP_TIMEOUT")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.Fatal().Msg("UDL_STOP_TIMEOUT is invalid: \"" + value + "\" is not a duration like \"25s\"")
+	}
+	return timeout
+}
+
+// getExecutor returns the executor that replaces UDL with the application when --exec or UDL_EXEC is set, or the
+// executor that runs the application as a child process otherwise.
+func getExecutor(argparser argparsers.ArgParser) executors.Executor {
+	if argparser.HasExecFlag() || getBoolEnv("UDL_EXEC", false) {
+		return executors.ExecReplace{}
+	}
+
+	return executors.ExecuteAndWait{
+		ProcessGroup: getBoolEnv("UDL_PROCESS_GROUP", false),
+		Subreaper:    getBoolEnv("UDL_SUBREAPER", false),
+		StopSignal:   getStopSignal(),
+		StopTimeout:  getStopTimeout(),
+	}
+}
+
 func systemExit() {
 	var argparser argparsers.ArgParser = argparsers.SimpleArgParser{}
-	var executor executors.Executor = executors.ExecuteAndWait{}
+	executor := getExecutor(argparser)
 
 	// wrap a call to an external executable if supplied
 	if argparser.HasExecutable() {
@@ -152,26 +293,43 @@ func systemExit() {
 	}
 }
 
+// startupErrorMessage returns the message logged when the application is not started because of an error applying
+// the env vars. Every env var that was not applied is listed with the reason it failed.
+func startupErrorMessage(err error) string {
+	var unappliedError *customerror.UnappliedError
+	if errors.As(err, &unappliedError) {
+		messages := []string{}
+		for _, unapplied := range unappliedError.Errors {
+			messages = append(messages, "\""+unapplied.EnvVar+"\": "+unapplied.Error())
+		}
+
+		return strconv.Itoa(len(unappliedError.Errors)) + " environment variables were not applied: " +
+			strings.Join(messages, "; ") + ". Set UDL_STRICT to false to start the application anyway."
+	}
+
+	var customError *customerror.UdlError
+	if errors.As(err, &customError) {
+		var pathError *fs.PathError
+		if errors.As(customError.Err, &pathError) {
+			return "Environment variable \"" + customError.EnvVar + "\"" +
+				" ran operation \"" + pathError.Op + "\"" +
+				" that failed at path \"" + pathError.Path + "\"" +
+				" with error \"" + pathError.Err.Error() + "\"." +
+				" This is usually a permission error. Make sure the Docker user has permission to this path."
+		}
+
+		return "Environment variable \"" + customError.EnvVar + "\" was not applied: " + customError.Error()
+	}
+
+	return err.Error()
+}
+
 func main() {
 	setLogging()
 	err := doScanning()
 
 	if err != nil {
-
-		var customError *customerror.UdlError
-		if errors.As(err, &customError) {
-			var pathError *fs.PathError
-			if errors.As(customError.Err, &pathError) {
-				panic("Environment variable \"" + customError.EnvVar + "\"" +
-					" ran operation \"" + pathError.Op + "\"" +
-					" that failed at path \"" + pathError.Path + "\"" +
-					" with error \"" + pathError.Err.Error() + "\"." +
-					" This is usually a permission error. Make sure the Docker user has permission to this path.")
-			}
-		} else {
-			panic(err.Error())
-		}
-
+		log.Fatal().Msg(startupErrorMessage(err))
 	}
 
 	systemExit()
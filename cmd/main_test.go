@@ -2,8 +2,11 @@ package main
 
 import (
 	b64 "encoding/base64"
+	"errors"
+	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/customerror"
 	"github.com/mcasperson/UltimateDockerLauncher/cmd/internal/prefixes"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -282,6 +285,104 @@ func TestMainIniTwo(t *testing.T) {
 	}
 }
 
+func TestMainXml(t *testing.T) {
+	xmlExample := "<configuration><add key=\"Db\" value=\"localhost\"/></configuration>"
+	xmlExampleProcessed := "<configuration><add key=\"Db\" value=\"5\"/></configuration>"
+
+	file, err := os.CreateTemp("", "file*.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", xmlExample)
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][/configuration/add[1]/@value]", "5")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	contentsString := strings.TrimSpace(string(contents))
+	if contentsString != xmlExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + contentsString + " expected " + xmlExampleProcessed)
+	}
+}
+
+func TestMainXmlTwo(t *testing.T) {
+	xmlExample := "<configuration><add key=\"Db\" value=\"localhost\"/></configuration>"
+	xmlExampleProcessed := "<configuration><add key=\"Db\" value=\"5\"/></configuration>"
+
+	file, err := os.CreateTemp("", "file*.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", xmlExample)
+	t.Setenv("UDL_SETVALUE_1", "["+file.Name()+"][/configuration/add[@key='Db']/@value]5")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	contentsString := strings.TrimSpace(string(contents))
+	if contentsString != xmlExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + contentsString + " expected " + xmlExampleProcessed)
+	}
+}
+
+func TestMainDelete(t *testing.T) {
+	jsonExample := "{\"whatever\":\"value\",\"other\":\"value\"}"
+	jsonProcessedExample := "{\"other\":\"value\"}"
+
+	file, err := os.CreateTemp("", "file*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", jsonExample)
+	t.Setenv("UDL_DELETEVALUE["+file.Name()+"][whatever]", "")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != jsonProcessedExample {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + jsonProcessedExample)
+	}
+}
+
+func TestMainDeleteTwo(t *testing.T) {
+	jsonExample := "{\"whatever\":\"value\",\"other\":\"value\"}"
+	jsonProcessedExample := "{\"other\":\"value\"}"
+
+	file, err := os.CreateTemp("", "file*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", jsonExample)
+	t.Setenv("UDL_DELETEVALUE_1", "["+file.Name()+"][whatever]")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != jsonProcessedExample {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + jsonProcessedExample)
+	}
+}
+
 func TestMainWriteFile(t *testing.T) {
 	jsonExample := "{\"whatever\":\"value\"}"
 
@@ -417,3 +518,281 @@ func TestMainBase64WithPrefixTwo(t *testing.T) {
 		t.Fatal("File contents should have matched")
 	}
 }
+
+func TestMainProperties(t *testing.T) {
+	propertiesExample := "# Server settings\nserver.port=8080\n"
+	propertiesExampleProcessed := "# Server settings\nserver.port=9090\n"
+
+	file, err := os.CreateTemp("", "file*.properties")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", propertiesExample)
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][server.port]", "9090")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != propertiesExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + propertiesExampleProcessed)
+	}
+}
+
+func TestMainDotenv(t *testing.T) {
+	dotenvExample := "# Database\nDB_HOST=localhost\n"
+	dotenvExampleProcessed := "# Database\nDB_HOST=db.example.org\n"
+
+	file, err := os.CreateTemp("", "file*.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", dotenvExample)
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][DB_HOST]", "db.example.org")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != dotenvExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + dotenvExampleProcessed)
+	}
+}
+
+func TestMainHcl(t *testing.T) {
+	hclExample := "listener \"tcp\" {\n  address     = \"0.0.0.0:8200\"\n  tls_disable = 1\n}\n"
+	hclExampleProcessed := "listener \"tcp\" {\n  address     = \"127.0.0.1:8200\"\n  tls_disable = 0\n}\n"
+
+	file, err := os.CreateTemp("", "file*.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", hclExample)
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][listener:tcp:address]", "127.0.0.1:8200")
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][listener:tcp:tls_disable]", "0")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != hclExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + hclExampleProcessed)
+	}
+}
+
+func TestMainJsonWithComments(t *testing.T) {
+	jsonExample := "{\n  // The port to listen on\n  \"port\": 80,\n}\n"
+	jsonExampleProcessed := "{\n  // The port to listen on\n  \"port\": 8080,\n}\n"
+
+	file, err := os.CreateTemp("", "file*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", jsonExample)
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][port]", "8080")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != jsonExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + jsonExampleProcessed)
+	}
+}
+
+func TestMainYamlMultipleDocuments(t *testing.T) {
+	yamlExample := "kind: Deployment\nspec:\n  replicas: 1\n---\nkind: Service\nspec:\n  port: 80\n"
+	yamlExampleProcessed := "kind: Deployment\nspec:\n  replicas: 3\n---\nkind: Service\nspec:\n  port: 8080\n"
+
+	file, err := os.CreateTemp("", "file*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", yamlExample)
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][#0:spec:replicas]", "3")
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][#1:spec:port]", "8080")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != yamlExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + yamlExampleProcessed)
+	}
+}
+
+func TestMainDetectFormat(t *testing.T) {
+	tomlExample := "[server]\nport = 80\n"
+	tomlExampleProcessed := "[server]\nport = 8080\n"
+
+	file, err := os.CreateTemp("", "file*.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", tomlExample)
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][server:port]", "8080")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != tomlExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + tomlExampleProcessed)
+	}
+}
+
+func TestMainFormatOverride(t *testing.T) {
+	dotenvExample := "PORT=80\n"
+	dotenvExampleProcessed := "PORT=8080\n"
+
+	file, err := os.CreateTemp("", "file*.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	// Without the override, the file would be detected as an INI file
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", dotenvExample)
+	t.Setenv("UDL_FORMAT_APP", "["+file.Name()+"]dotenv")
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][PORT]", "8080")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != dotenvExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + dotenvExampleProcessed)
+	}
+}
+
+func TestMainStrict(t *testing.T) {
+	t.Setenv("UDL_SETVALUE[/tmp/does-not-exist/config.json][port]", "8080")
+	t.Setenv("UDL_DELETEVALUE_PORT", "[/tmp/does-not-exist/config.yaml][port]")
+	err := doScanning()
+
+	var unappliedError *customerror.UnappliedError
+	if !errors.As(err, &unappliedError) || len(unappliedError.Errors) != 2 {
+		t.Fatal("Env vars that can not be applied must fail in strict mode")
+	}
+
+	t.Setenv("UDL_STRICT", "false")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal("Env vars that can not be applied must be ignored when strict mode is disabled")
+	}
+}
+
+func TestMainStrictMalformed(t *testing.T) {
+	file, err := os.CreateTemp("", "*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", "{\"a\": 1}")
+	t.Setenv("UDL_SETVALUE["+file.Name()+"][a][integer]", "5")
+	t.Setenv("UDL_SETVALUE_X", "["+file.Name()+"[a]5")
+	t.Setenv("UDL_DELETEVALUE["+file.Name()+"][a]junk", "")
+	t.Setenv("UDL_DELETEVALUE_Y", "["+file.Name()+"[a]")
+	err = doScanning()
+
+	var unappliedError *customerror.UnappliedError
+	if !errors.As(err, &unappliedError) {
+		t.Fatal("Malformed env vars must fail in strict mode")
+	}
+
+	envVars := []string{}
+	for _, unapplied := range unappliedError.Errors {
+		envVars = append(envVars, unapplied.EnvVar)
+	}
+	sort.Strings(envVars)
+
+	expected := []string{
+		"UDL_DELETEVALUE[" + file.Name() + "][a]junk",
+		"UDL_DELETEVALUE_Y",
+		"UDL_SETVALUE[" + file.Name() + "][a][integer]",
+		"UDL_SETVALUE_X",
+	}
+
+	if strings.Join(envVars, ",") != strings.Join(expected, ",") {
+		t.Fatal("Each malformed env var must be reported (was " + strings.Join(envVars, ",") + ")")
+	}
+}
+
+func TestStartupErrorMessage(t *testing.T) {
+	tests := []struct {
+		err     error
+		message string
+	}{
+		{
+			&customerror.UnappliedError{Errors: []*customerror.UdlError{
+				{EnvVar: "UDL_SETVALUE[/app/config.json][port]", Err: errors.New("the file /app/config.json does not exist")},
+				{EnvVar: "UDL_DELETEVALUE_PORT", Err: errors.New("index 5 is out of range")},
+			}},
+			"2 environment variables were not applied: " +
+				"\"UDL_SETVALUE[/app/config.json][port]\": the file /app/config.json does not exist; " +
+				"\"UDL_DELETEVALUE_PORT\": index 5 is out of range. Set UDL_STRICT to false to start the application anyway.",
+		},
+		{
+			&customerror.UdlError{EnvVar: "UDL_WRITEFILE[/app/x[y]", Err: errors.New("unclosed bracket")},
+			"Environment variable \"UDL_WRITEFILE[/app/x[y]\" was not applied: unclosed bracket",
+		},
+	}
+
+	for _, test := range tests {
+		if actual := startupErrorMessage(test.err); actual != test.message {
+			t.Fatal("The message must be \"" + test.message + "\" (was \"" + actual + "\")")
+		}
+	}
+}
+
+func TestMainReplace(t *testing.T) {
+	htmlExample := "<script>window.apiUrl = \"__API_URL__\";</script>\n"
+	htmlExampleProcessed := "<script>window.apiUrl = \"https://api.example.org\";</script>\n"
+
+	file, err := os.CreateTemp("", "file*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	t.Setenv("UDL_WRITEFILE["+file.Name()+"]", htmlExample)
+	t.Setenv("UDL_REPLACE_API_URL", "["+file.Name()+"][__API_URL__]https://api.example.org")
+	err = doScanning()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := os.ReadFile(file.Name())
+	if string(contents) != htmlExampleProcessed {
+		t.Fatal("File contents should have matched. Was " + string(contents) + " expected " + htmlExampleProcessed)
+	}
+}